@@ -19,9 +19,12 @@ import (
 	"github.com/globalsign/mgo/bson"
 	"github.com/ofs/alpha-dataset-api/models"
 	"github.com/ofs/alpha-scripts/elasticsearch/load-courses/elasticsearch"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 )
 
 var (
+	configFile string
+
 	esDestURL        = "http://localhost:9200"
 	esDestIndex      = "courses"
 	esSignedRequests bool
@@ -30,6 +33,7 @@ var (
 	mongoDatabase   = "courses"
 	mongoCollection = "courses"
 	mongoSize       = 500
+	release         string
 )
 
 var (
@@ -40,16 +44,39 @@ var (
 )
 
 func main() {
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the database, collection and index, overridden by their flags")
 	flag.StringVar(&mongoURL, "mongo-url", mongoURL, "mongoDB URL")
 	flag.StringVar(&mongoDatabase, "mongo-database", mongoDatabase, "mongoDB database")
 	flag.StringVar(&mongoCollection, "mongo-collection", mongoCollection, "mongoDB collection")
 	flag.StringVar(&esDestURL, "es-dest-url", esDestURL, "elasticsearch destination URL")
 	flag.StringVar(&esDestIndex, "es-dest-index", esDestIndex, "elasticsearch index")
+	flag.StringVar(&release, "release", release, "HESA release of the courses to index, every course is indexed if not set")
 	flag.BoolVar(&esSignedRequests, "es-signed-requests", esSignedRequests, "sign elasticsearch requests")
 	flag.Parse()
 
 	log.Namespace = "alpha-elasticsearch-loadinator"
 
+	ctx := context.Background()
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.ErrorCtx(ctx, errors.WithMessage(err, "failed to read config file"), log.Data{"config": configFile})
+		os.Exit(1)
+	}
+
+	// Names given as flags win over the config
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["mongo-collection"] {
+		mongoCollection = cfg.Collection(mongoDatabase, mongoCollection)
+	}
+	if !set["mongo-database"] {
+		mongoDatabase = cfg.Database(mongoDatabase)
+	}
+	if !set["es-dest-index"] {
+		esDestIndex = cfg.Index(esDestIndex)
+	}
+
 	logData := log.Data{
 		"mongo-url":          mongoURL,
 		"mongo-database":     mongoDatabase,
@@ -57,10 +84,9 @@ func main() {
 		"es-dest-url":        esDestURL,
 		"es-dest-index":      esDestIndex,
 		"es-signed-requests": esSignedRequests,
+		"release":            release,
 	}
 
-	ctx := context.Background()
-
 	s, err := mgo.Dial(mongoURL)
 	if err != nil {
 		log.ErrorCtx(ctx, errors.WithMessage(err, "error creating mongoDB session"), logData)
@@ -102,7 +128,12 @@ func main() {
 	go status(ctx)
 
 	// Iterate mongo course data
-	it := s.DB(mongoDatabase).C(mongoCollection).Find(bson.M{}).Batch(mongoSize).Iter()
+	query := bson.M{}
+	if release != "" {
+		query["release"] = release
+	}
+
+	it := s.DB(mongoDatabase).C(mongoCollection).Find(query).Batch(mongoSize).Iter()
 
 	for {
 		courses := make([]*models.Course, mongoSize)
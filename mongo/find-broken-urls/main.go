@@ -16,12 +16,14 @@ import (
 	"github.com/ONSdigital/go-ns/log"
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
 )
 
 var (
-	mongoURI string
-	wg       sync.WaitGroup
+	mongoURI   string
+	configFile string
+	wg         sync.WaitGroup
 
 	readCountCh    = make(chan int)
 	failedCourseCh = make(chan int)
@@ -37,8 +39,17 @@ var (
 
 func main() {
 	flag.StringVar(&mongoURI, "mongo-uri", mongoURI, "mongoDB URI")
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the databases and collections, names not in it keep their defaults")
 	flag.Parse()
 
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.ErrorC("failed to read config file", err, log.Data{"config": configFile})
+		os.Exit(1)
+	}
+	mongoCollection = cfg.Collection(mongoDatabase, mongoCollection)
+	mongoDatabase = cfg.Database(mongoDatabase)
+
 	if mongoURI == "" {
 		log.Error(errors.New("missing mongo-uri flag"), nil)
 		os.Exit(1)
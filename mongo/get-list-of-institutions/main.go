@@ -12,12 +12,14 @@ import (
 	"github.com/ONSdigital/go-ns/log"
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
 )
 
 var (
 	mongoURI string
 
+	configFile string
 	database   = "courses"
 	collection = "courses"
 	filename   = "institutions.json"
@@ -26,10 +28,19 @@ var (
 
 func main() {
 	flag.StringVar(&mongoURI, "mongo-uri", mongoURI, "mongoDB URI")
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the databases and collections, names not in it keep their defaults")
 	flag.StringVar(&filename, "filename", filename, "filename")
 	flag.IntVar(&mongoSize, "size", mongoSize, "size")
 	flag.Parse()
 
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.ErrorC("failed to read config file", err, log.Data{"config": configFile})
+		os.Exit(1)
+	}
+	collection = cfg.Collection(database, collection)
+	database = cfg.Database(database)
+
 	if mongoURI == "" {
 		log.Error(errors.New("missing mongo-url flag"), nil)
 		os.Exit(1)
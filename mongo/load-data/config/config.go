@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config names the csv files, databases, collections and Elasticsearch
+// indexes used by the builders, the Elasticsearch loader and the report tools,
+// and the public api the builders link institutions and courses to.
+// Each name is looked up by its default, so a name missing from the config
+// keeps its default and an empty config is the production layout.
+//
+// An environment variable overrides the config file:
+//
+//	DATABASE_<DATABASE>                e.g. DATABASE_COURSES=courses-staging
+//	COLLECTION_<DATABASE>_<COLLECTION> e.g. COLLECTION_STATISTICS_JOB_LIST=job-list-2019
+//	FILE_<DATASET>                     e.g. FILE_UKPRN_LOOKUP=UNISTATS_UKPRN_lookup_20190901
+//	INDEX_<INDEX>                      e.g. INDEX_COURSES=courses-staging
+//	API_URL                            e.g. API_URL=https://api.example.com
+//	LINK_<LINK>                        e.g. LINK_COURSE={api_url}/courses/{kis_course_id}
+//
+// where each key is upper cased with anything other than a letter or digit
+// replaced by an underscore.
+type Config struct {
+	Databases   map[string]string `json:"databases,omitempty"`   // keyed by default database name
+	Collections map[string]string `json:"collections,omitempty"` // keyed by default database and collection name, e.g. statistics.job-list
+	Files       map[string]string `json:"files,omitempty"`       // keyed by dataset, names exclude the .csv extension
+	Indexes     map[string]string `json:"indexes,omitempty"`     // Elasticsearch indexes keyed by default name
+
+	APIURL        string            `json:"api_url,omitempty"` // base url of the public api links are built from
+	LinkTemplates map[string]string `json:"links,omitempty"`   // link templates keyed by link, see DefaultLinks
+}
+
+// Load reads the JSON config file at path, an empty path gives the defaults
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	if path == "" {
+		return c, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(c); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// Database returns the name of the database known by default as name
+func (c *Config) Database(name string) string {
+	return c.lookup("DATABASE", name, c.databases(), name)
+}
+
+// Collection returns the name of a collection, given the default names of it
+// and the database holding it
+func (c *Config) Collection(database, name string) string {
+	return c.lookup("COLLECTION", database+"."+name, c.collections(), name)
+}
+
+// File returns the name, without extension, of the csv file a dataset is
+// read from, fileName if it is not configured
+func (c *Config) File(dataset, fileName string) string {
+	return c.lookup("FILE", dataset, c.files(), fileName)
+}
+
+// Index returns the name of the Elasticsearch index known by default as name
+func (c *Config) Index(name string) string {
+	return c.lookup("INDEX", name, c.indexes(), name)
+}
+
+// lookup returns the environment override of key, then its name in names,
+// then the default
+func (c *Config) lookup(prefix, key string, names map[string]string, defaultName string) string {
+	if name := os.Getenv(envName(prefix, key)); name != "" {
+		return name
+	}
+
+	if name := names[key]; name != "" {
+		return name
+	}
+
+	return defaultName
+}
+
+// The accessors below let a nil config give the defaults
+
+func (c *Config) databases() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.Databases
+}
+
+func (c *Config) collections() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.Collections
+}
+
+func (c *Config) files() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.Files
+}
+
+func (c *Config) indexes() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.Indexes
+}
+
+func (c *Config) apiURL() string {
+	if c == nil {
+		return ""
+	}
+	return c.APIURL
+}
+
+func (c *Config) linkTemplates() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.LinkTemplates
+}
+
+// envName returns the environment variable overriding key
+func envName(prefix, key string) string {
+	return prefix + "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := filepath.Join(dir, "config.json")
+	contents := `{
+		"databases": {"statistics": "statistics-staging"},
+		"collections": {"statistics.job-list": "job-list-2019"},
+		"files": {"SBJ": "SBJ2019"}
+	}`
+	if err = ioutil.WriteFile(location, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []struct{ got, expected string }{
+		{c.Database("statistics"), "statistics-staging"},
+		{c.Database("courses"), "courses"},
+		{c.Collection("statistics", "job-list"), "job-list-2019"},
+		{c.Collection("statistics", "job-type"), "job-type"},
+		{c.File("SBJ", "SBJ"), "SBJ2019"},
+		{c.File("KISAIMS", "kisaims"), "kisaims"},
+		{c.Index("courses"), "courses"},
+	} {
+		if name.got != name.expected {
+			t.Errorf("expected %s, got %s", name.expected, name.got)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := filepath.Join(dir, "config.json")
+	if err = ioutil.WriteFile(location, []byte(`{"database": {"courses": "courses-staging"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Load(location); err == nil {
+		t.Error("expected a misspelt section to be rejected")
+	}
+}
+
+func TestEnvironmentOverridesFile(t *testing.T) {
+	c := &Config{
+		Databases:   map[string]string{"courses": "courses-staging"},
+		Collections: map[string]string{"statistics.nhs-nss": "nhs-nss-2019"},
+	}
+
+	os.Setenv("DATABASE_COURSES", "courses-test")
+	os.Setenv("COLLECTION_STATISTICS_NHS_NSS", "nhs-nss-test")
+	os.Setenv("FILE_UKPRN_LOOKUP", "UNISTATS_UKPRN_lookup_20190901")
+	defer os.Unsetenv("DATABASE_COURSES")
+	defer os.Unsetenv("COLLECTION_STATISTICS_NHS_NSS")
+	defer os.Unsetenv("FILE_UKPRN_LOOKUP")
+
+	if name := c.Database("courses"); name != "courses-test" {
+		t.Errorf("expected environment to override database, got %s", name)
+	}
+
+	if name := c.Collection("statistics", "nhs-nss"); name != "nhs-nss-test" {
+		t.Errorf("expected environment to override collection, got %s", name)
+	}
+
+	// A nil config gives the defaults, less any environment overrides
+	var empty *Config
+	if name := empty.File("UKPRN-LOOKUP", "UNISTATS_UKPRN_lookup_20160901"); name != "UNISTATS_UKPRN_lookup_20190901" {
+		t.Errorf("expected environment to override file, got %s", name)
+	}
+
+	if name := empty.Collection("courses", "subjects"); name != "subjects" {
+		t.Errorf("expected default collection, got %s", name)
+	}
+}
+
+func TestExampleGivesDefaults(t *testing.T) {
+	c, err := Load("example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, name := range c.Collections {
+		if !strings.HasSuffix(key, "."+name) {
+			t.Errorf("expected example collection %s to be named by default, got %s", key, name)
+		}
+	}
+
+	if name := c.File("KISAIMS", ""); name != "kisaims" {
+		t.Errorf("expected example to name the kisaims file, got %s", name)
+	}
+
+	for link, template := range c.LinkTemplates {
+		if template != DefaultLinks[link] {
+			t.Errorf("expected example %s link template to be the default, got %s", link, template)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	c := &Config{
+		APIURL:        "https://api.example.com/v1/",
+		LinkTemplates: map[string]string{"course": "{api_url}/courses/{kis_course_id}/{kis_mode}?institution={institution}"},
+	}
+
+	links, err := c.Links("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []struct{ got, expected string }{
+		{links.Institution("10007789"), "https://api.example.com/v1/institutions/10007789"},
+		{links.Courses("10007789"), "https://api.example.com/v1/institutions/10007789/courses"},
+		{links.Course("10007789", "U1234", "1"), "https://api.example.com/v1/courses/U1234/1?institution=10007789"},
+	} {
+		if link.got != link.expected {
+			t.Errorf("expected %s, got %s", link.expected, link.got)
+		}
+	}
+
+	// The api url given overrides the config file
+	if links, err = c.Links("http://localhost:10000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if link := links.Institution("10007789"); link != "http://localhost:10000/institutions/10007789" {
+		t.Errorf("expected api url to override config file, got %s", link)
+	}
+}
+
+func TestLinksRejectsBadSettings(t *testing.T) {
+	for name, c := range map[string]*Config{
+		"missing api url":      nil,
+		"relative api url":     {APIURL: "api.example.com"},
+		"unknown link":         {APIURL: "https://api.example.com", LinkTemplates: map[string]string{"location": "{api_url}/locations"}},
+		"unknown placeholder":  {APIURL: "https://api.example.com", LinkTemplates: map[string]string{"courses": "{api_url}/institutions/{ukprn}/courses"}},
+		"course only template": {APIURL: "https://api.example.com", LinkTemplates: map[string]string{"institution": "{api_url}/institutions/{kis_course_id}"}},
+	} {
+		if _, err := c.Links(""); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultLinks are the templates the links of institutions and courses are
+// built from, keyed by link. {api_url} is replaced by the base url of the
+// public api, {institution} by the ukprn an institution is published under
+// and {kis_course_id} and {kis_mode} by those of a course.
+var DefaultLinks = map[string]string{
+	"institution": "{api_url}/institutions/{institution}",
+	"courses":     "{api_url}/institutions/{institution}/courses",
+	"course":      "{api_url}/institutions/{institution}/courses/{kis_course_id}/modes/{kis_mode}",
+}
+
+// placeholders are those each link's template may hold
+var placeholders = map[string][]string{
+	"institution": {"api_url", "institution"},
+	"courses":     {"api_url", "institution"},
+	"course":      {"api_url", "institution", "kis_course_id", "kis_mode"},
+}
+
+var placeholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// ErrMissingAPIURL is returned when links are needed but no api url is set
+var ErrMissingAPIURL = errors.New("missing api url, set api_url in the config file, API_URL or the api-url flag")
+
+// Links builds the links of institutions and courses from their templates
+type Links struct {
+	apiURL    string
+	templates map[string]string
+}
+
+// Links returns the links of the public api at apiURL, or at the configured
+// api url if apiURL is empty. The api url overrides api_url in the config
+// file and LINK_<LINK>, e.g. LINK_COURSE, overrides the template of a link.
+func (c *Config) Links(apiURL string) (*Links, error) {
+	if apiURL == "" {
+		apiURL = c.lookup("API", "url", nil, c.apiURL())
+	}
+
+	apiURL = strings.TrimRight(apiURL, "/")
+	if apiURL == "" {
+		return nil, ErrMissingAPIURL
+	}
+
+	if u, err := url.Parse(apiURL); err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("api url %q is not an absolute url", apiURL)
+	}
+
+	for link := range c.linkTemplates() {
+		if _, ok := DefaultLinks[link]; !ok {
+			return nil, fmt.Errorf("unknown link %q in config file", link)
+		}
+	}
+
+	l := &Links{apiURL: apiURL, templates: make(map[string]string)}
+	for link, template := range DefaultLinks {
+		template = c.lookup("LINK", link, c.linkTemplates(), template)
+		if err := checkTemplate(link, template); err != nil {
+			return nil, err
+		}
+
+		l.templates[link] = template
+	}
+
+	return l, nil
+}
+
+// checkTemplate reports a placeholder a link's template cannot fill
+func checkTemplate(link, template string) error {
+	for _, p := range placeholder.FindAllString(template, -1) {
+		known := false
+		for _, name := range placeholders[link] {
+			known = known || p == "{"+name+"}"
+		}
+
+		if !known {
+			return fmt.Errorf("link template %q of %s has unknown placeholder %s", template, link, p)
+		}
+	}
+
+	return nil
+}
+
+// Institution returns the link of an institution
+func (l *Links) Institution(institution string) string {
+	return l.fill("institution", "{institution}", institution)
+}
+
+// Courses returns the link of an institution's courses
+func (l *Links) Courses(institution string) string {
+	return l.fill("courses", "{institution}", institution)
+}
+
+// Course returns the link of a course in one mode
+func (l *Links) Course(institution, kisCourseID, kisMode string) string {
+	return l.fill("course", "{institution}", institution, "{kis_course_id}", kisCourseID, "{kis_mode}", kisMode)
+}
+
+// fill replaces the placeholders of a link's template, given as pairs of
+// placeholder and value
+func (l *Links) fill(link string, values ...string) string {
+	return strings.NewReplacer(append([]string{"{api_url}", l.apiURL}, values...)...).Replace(l.templates[link])
+}
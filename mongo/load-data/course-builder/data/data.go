@@ -16,6 +16,7 @@ type Course struct {
 	Mode                *Mode              `bson:"mode"` // enum - part time, full time, both
 	NHSFunded           *NHSFunded         `bson:"nhs_funded,omitempty"`
 	Qualification       *Qualification     `bson:"qualification"`
+	Release             string             `bson:"release"` // HESA release the course was loaded from
 	SandwichYear        *Availability      `bson:"sandwich_year"`
 	Statistics          *Statistics        `bson:"statistics,omitempty"`
 	Subject             *Subject           `bson:"subject"`
@@ -82,6 +83,19 @@ type Location struct {
 	Latitude  string    `bson:"latitude"`
 	Longitude string    `bson:"longitude"`
 	Name      *Language `bson:"name"`
+	Point     *Point    `bson:"point,omitempty"`
+}
+
+// Point is a GeoJSON point, stored alongside the latitude and longitude a
+// location is published with so that courses can be found with $near
+type Point struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"` // longitude, latitude
+}
+
+// NewPoint returns the GeoJSON point at the latitude and longitude
+func NewPoint(latitude, longitude float64) *Point {
+	return &Point{Type: "Point", Coordinates: []float64{longitude, latitude}}
 }
 
 // Mode represents an object referring to the type of course
@@ -2,67 +2,152 @@ package data
 
 // ContinuationRaw represents the continuation statistical data for course (or subject)
 type ContinuationRaw struct {
+	KISCourseID                  string   `bson:"kis_course_id"`
+	KISMode                      string   `bson:"kis_mode"`
+	PublicUKPRN                  string   `bson:"public_ukprn"`
 	AggregationLevel             int      `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents             int      `bson:"number_of_students,omitempty"`
-	ContinuingWithProvider       int      `bson:"proportion_of_students_continuing_with_provider_after_first_year_on_course,omitempty"`
-	Dormant                      int      `bson:"proportion_of_students_dormant_after_first_year_on_course,omitempty"`
-	GainingIntendedAwardOrHigher int      `bson:"proportion_of_students_gaining_intended_award_or_higher,omitempty"`
-	GainedLowerAward             int      `bson:"proportion_of_students_gained_lower_award,omitempty"`
-	LeavingCourse                int      `bson:"proportion_of_students_leaving_course,omitempty"`
+	NumberOfStudents             *int     `bson:"number_of_students,omitempty"`
+	ContinuingWithProvider       *int     `bson:"proportion_of_students_continuing_with_provider_after_first_year_on_course,omitempty"`
+	Dormant                      *int     `bson:"proportion_of_students_dormant_after_first_year_on_course,omitempty"`
+	GainingIntendedAwardOrHigher *int     `bson:"proportion_of_students_gaining_intended_award_or_higher,omitempty"`
+	GainedLowerAward             *int     `bson:"proportion_of_students_gained_lower_award,omitempty"`
+	LeavingCourse                *int     `bson:"proportion_of_students_leaving_course,omitempty"`
 	Subject                      *Subject `bson:"subject,omitempty"`
 	Unavailable                  string   `bson:"unavailable,omitempty"`
 }
 
+// DegreeClassRaw represents the degree class statistical data for course (or subject) stored in its raw state
+type DegreeClassRaw struct {
+	KISCourseID        string   `bson:"kis_course_id"`
+	KISMode            string   `bson:"kis_mode"`
+	PublicUKPRN        string   `bson:"public_ukprn"`
+	AggregationLevel   int      `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents   *int     `bson:"number_of_students,omitempty"`
+	Distinction        *int     `bson:"proportion_of_students_gaining_distinction,omitempty"`
+	FirstClass         *int     `bson:"proportion_of_students_gaining_first_class,omitempty"`
+	LowerSecondClass   *int     `bson:"proportion_of_students_gaining_lower_second_class,omitempty"`
+	Merit              *int     `bson:"proportion_of_students_gaining_merit,omitempty"`
+	OrdinaryDegree     *int     `bson:"proportion_of_students_gaining_ordinary_degree,omitempty"`
+	OtherHonoursDegree *int     `bson:"proportion_of_students_gaining_other_honours_degree,omitempty"`
+	Pass               *int     `bson:"proportion_of_students_gaining_pass,omitempty"`
+	UnclassifiedDegree *int     `bson:"proportion_of_students_gaining_unclassified_degree,omitempty"`
+	UpperSecondClass   *int     `bson:"proportion_of_students_gaining_upper_second_class,omitempty"`
+	Subject            *Subject `bson:"subject,omitempty"`
+	Unavailable        string   `bson:"unavailable,omitempty"`
+}
+
 // EmploymentRaw represents the employment statistical data for course (or subject)
 type EmploymentRaw struct {
+	KISCourseID                string   `bson:"kis_course_id"`
+	KISMode                    string   `bson:"kis_mode"`
+	PublicUKPRN                string   `bson:"public_ukprn"`
 	AggregationLevel           int      `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents           int      `bson:"number_of_students,omitempty"`
-	AssumedToBeUnemployed      int      `bson:"proportion_of_students_assumed_to_be_unemployed,omitempty"`
-	InStudy                    int      `bson:"proportion_of_students_in_study,omitempty"`
-	InWork                     int      `bson:"proportion_of_students_in_work,omitempty"`
-	InWorkAndStudy             int      `bson:"proportion_of_students_in_work_and_study,omitempty"`
-	InWorkOrStudy              int      `bson:"proportion_of_students_in_work_or_study,omitempty"`
-	NotAvailableForWorkOrStudy int      `bson:"proportion_of_students_who_are_not_available_for_work_or_study,omitempty"`
-	ResponseRate               int      `bson:"response_rate,omitempty"`
+	NumberOfStudents           *int     `bson:"number_of_students,omitempty"`
+	AssumedToBeUnemployed      *int     `bson:"proportion_of_students_assumed_to_be_unemployed,omitempty"`
+	InStudy                    *int     `bson:"proportion_of_students_in_study,omitempty"`
+	InWork                     *int     `bson:"proportion_of_students_in_work,omitempty"`
+	InWorkAndStudy             *int     `bson:"proportion_of_students_in_work_and_study,omitempty"`
+	InWorkOrStudy              *int     `bson:"proportion_of_students_in_work_or_study,omitempty"`
+	NotAvailableForWorkOrStudy *int     `bson:"proportion_of_students_who_are_not_available_for_work_or_study,omitempty"`
+	ResponseRate               *int     `bson:"response_rate,omitempty"`
 	Subject                    *Subject `bson:"subject,omitempty"`
 	Unavailable                string   `bson:"unavailable,omitempty"`
 }
 
+// EntryRaw represents the entry qualifications statistical data for course (or subject) stored in its raw state
+type EntryRaw struct {
+	KISCourseID               string   `bson:"kis_course_id"`
+	KISMode                   string   `bson:"kis_mode"`
+	PublicUKPRN               string   `bson:"public_ukprn"`
+	AggregationLevel          int      `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents          *int     `bson:"number_of_students,omitempty"`
+	AccessCourse              *int     `bson:"proportion_of_students_with_access_course,omitempty"`
+	ALevel                    *int     `bson:"proportion_of_students_with_a_level,omitempty"`
+	Baccalaureate             *int     `bson:"proportion_of_students_with_baccalaureate,omitempty"`
+	Degree                    *int     `bson:"proportion_of_students_with_degree,omitempty"`
+	Foundation                *int     `bson:"proportion_of_students_with_foundation,omitempty"`
+	NoQualifications          *int     `bson:"proportion_of_students_with_no_qualifications,omitempty"`
+	OtherQualifications       *int     `bson:"proportion_of_students_with_other_qualifications,omitempty"`
+	OtherHigherEducationQuals *int     `bson:"proportion_of_students_with_another_higher_education_qualifications,omitempty"`
+	Subject                   *Subject `bson:"subject,omitempty"`
+	Unavailable               string   `bson:"unavailable,omitempty"`
+}
+
 // JobTypeRaw represents the job type statistical data for course (or subject)
 type JobTypeRaw struct {
+	KISCourseID                     string   `bson:"kis_course_id"`
+	KISMode                         string   `bson:"kis_mode"`
+	PublicUKPRN                     string   `bson:"public_ukprn"`
 	AggregationLevel                int      `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents                int      `bson:"number_of_students,omitempty"`
-	ProfessionalOrManagerialJobs    int      `bson:"proportion_of_students_in_professional_or_managerial_jobs,omitempty"`
-	NonProfessionalOrManagerialJobs int      `bson:"proportion_of_students_in_non_professional_or_managerial_jobs,omitempty"`
-	UnknownProfessions              int      `bson:"proportion_of_students_in_unknown_professions,omitempty"`
-	ResponseRate                    int      `bson:"response_rate,omitempty"`
+	NumberOfStudents                *int     `bson:"number_of_students,omitempty"`
+	ProfessionalOrManagerialJobs    *int     `bson:"proportion_of_students_in_professional_or_managerial_jobs,omitempty"`
+	NonProfessionalOrManagerialJobs *int     `bson:"proportion_of_students_in_non_professional_or_managerial_jobs,omitempty"`
+	UnknownProfessions              *int     `bson:"proportion_of_students_in_unknown_professions,omitempty"`
+	ResponseRate                    *int     `bson:"response_rate,omitempty"`
 	Subject                         *Subject `bson:"subject,omitempty"`
 	Unavailable                     string   `bson:"unavailable,omitempty"`
 }
 
 // LEORaw represents the LEO statistical data for course (or subject)
 type LEORaw struct {
+	KISCourseID         string   `bson:"kis_course_id"`
+	KISMode             string   `bson:"kis_mode"`
+	PublicUKPRN         string   `bson:"public_ukprn"`
 	AggregationLevel    int      `bson:"aggregation_level,omitempty"` // enum
-	HigherQuartileRange int      `bson:"higher_quartile_range,omitempty"`
-	LowerQuartileRange  int      `bson:"lower_quartile_range,omitempty"`
-	Median              int      `bson:"median,omitempty"`
-	NumberOfGraduates   int      `bson:"number_of_graduates,omitempty"`
+	HigherQuartileRange *int     `bson:"higher_quartile_range,omitempty"`
+	LowerQuartileRange  *int     `bson:"lower_quartile_range,omitempty"`
+	Median              *int     `bson:"median,omitempty"`
+	NumberOfGraduates   *int     `bson:"number_of_graduates,omitempty"`
 	Subject             *Subject `bson:"subject,omitempty"`
 	Unavailable         string   `bson:"unavailable,omitempty"`
 }
 
+// NSSRaw represents the National Student Survey, or NHS National Student
+// Survey, statistical data for course (or subject) stored in its raw state
+type NSSRaw struct {
+	KISCourseID      string      `bson:"kis_course_id"`
+	KISMode          string      `bson:"kis_mode"`
+	PublicUKPRN      string      `bson:"public_ukprn"`
+	AggregationLevel int         `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents *int        `bson:"number_of_students,omitempty"`
+	ResponseRate     *int        `bson:"response_rate,omitempty"`
+	Questions        []*Question `bson:"survey,omitempty"`
+	Subject          *Subject    `bson:"subject,omitempty"`
+	Unavailable      string      `bson:"unavailable,omitempty"`
+}
+
 // SalaryRaw represents the salary statistical data for course (or subject) stored in its raw state
 type SalaryRaw struct {
 	AggregationLevel                                int      `bson:"aggregation_level,omitempty"`                                     // SALAGG
 	InstitutionCourseSalarySixMonthsAfterGraduation *Stats   `bson:"institution_course_salary_six_months_after_graduation,omitempty"` // INST
 	KISMode                                         string   `bson:"kis_mode"`
 	KISCourseID                                     string   `bson:"kis_course_id"`
-	NumberOfStudents                                int      `bson:"number_of_students,omitempty"` // SALPOP
+	NumberOfStudents                                *int     `bson:"number_of_students,omitempty"` // SALPOP
 	PublicUKPRN                                     string   `bson:"public_ukprn"`
-	ResponseRate                                    int      `bson:"response_rate"`                                       // SALRESP_RATE
+	ResponseRate                                    *int     `bson:"response_rate,omitempty"`                             // SALRESP_RATE
 	Subject                                         *Subject `bson:"subject,omitempty"`                                   // SALSBJ
 	SubjectSalaryFortyMonthsAfterGraduation         *Stats   `bson:"subject_salary_40_months_after_graduation,omitempty"` // LD
 	SubjectSalarySixMonthsAfterGraduation           *Stats   `bson:"subject_salary_six_months_after_graduation,omitempty"`
 	UKPRN                                           string   `bson:"ukprn"`
 	Unavailable                                     string   `bson:"unavailable,omitempty"`
 }
+
+// TariffRaw represents the entry tariff points statistical data for course (or subject) stored in its raw state
+type TariffRaw struct {
+	KISCourseID      string        `bson:"kis_course_id"`
+	KISMode          string        `bson:"kis_mode"`
+	PublicUKPRN      string        `bson:"public_ukprn"`
+	AggregationLevel int           `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents *int          `bson:"number_of_students,omitempty"`
+	Tariffs          []*TariffBand `bson:"tariff,omitempty"`
+	Subject          *Subject      `bson:"subject,omitempty"`
+	Unavailable      string        `bson:"unavailable,omitempty"`
+}
+
+// SubjectRaw represents the subject of a course stored in its raw state
+type SubjectRaw struct {
+	KISCourseID string   `bson:"kis_course_id"`
+	KISMode     string   `bson:"kis_mode"`
+	PublicUKPRN string   `bson:"public_ukprn"`
+	Subject     *Subject `bson:"subject,omitempty"`
+}
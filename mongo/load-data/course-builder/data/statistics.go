@@ -3,18 +3,26 @@ package data
 // Statistics represents an object containing a list of statistical data for course (or subject)
 type Statistics struct {
 	Continuation []*Continuation `bson:"continuation,omitempty"`
+	DegreeClass  []*DegreeClass  `bson:"degree_class,omitempty"`
 	Employment   []*Employment   `bson:"employment,omitempty"`
+	Entry        []*Entry        `bson:"entry,omitempty"`
 	JobList      *JobList        `bson:"job_list,omitempty"`
 	JobType      []*JobType      `bson:"job_type,omitempty"`
 	LEO          []*LEO          `bson:"leo,omitempty"`
+	NHSNSS       []*NSS          `bson:"nhs_nss,omitempty"`
+	NSS          []*NSS          `bson:"nss,omitempty"`
 	Salary       []*Salary       `bson:"salary,omitempty"`
+	Tariff       []*Tariff       `bson:"tariff,omitempty"`
 }
 
 // Common represents the metadata relative to the job list statistical data for course (or subject)
 type Common struct {
+	KISCourseID      string   `bson:"kis_course_id"`
+	KISMode          string   `bson:"kis_mode"`
+	PublicUKPRN      string   `bson:"public_ukprn"`
 	AggregationLevel int      `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents int      `bson:"number_of_students,omitempty"`
-	ResponseRate     int      `bson:"response_rate,omitempty"`
+	NumberOfStudents *int     `bson:"number_of_students,omitempty"`
+	ResponseRate     *int     `bson:"response_rate,omitempty"`
 	Subject          *Subject `bson:"subject,omitempty"`
 	Unavailable      string   `bson:"unavailable,omitempty"`
 }
@@ -22,31 +30,64 @@ type Common struct {
 // Continuation represents the continuation statistical data for course (or subject)
 type Continuation struct {
 	AggregationLevel             int          `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents             int          `bson:"number_of_students,omitempty"`
-	ContinuingWithProvider       int          `bson:"proportion_of_students_continuing_with_provider_after_first_year_on_course,omitempty"`
-	Dormant                      int          `bson:"proportion_of_students_dormant_after_first_year_on_course,omitempty"`
-	GainingIntendedAwardOrHigher int          `bson:"proportion_of_students_gaining_intended_award_or_higher,omitempty"`
-	GainedLowerAward             int          `bson:"proportion_of_students_gained_lower_award,omitempty"`
-	LeavingCourse                int          `bson:"proportion_of_students_leaving_course,omitempty"`
+	NumberOfStudents             *int         `bson:"number_of_students,omitempty"`
+	ContinuingWithProvider       *int         `bson:"proportion_of_students_continuing_with_provider_after_first_year_on_course,omitempty"`
+	Dormant                      *int         `bson:"proportion_of_students_dormant_after_first_year_on_course,omitempty"`
+	GainingIntendedAwardOrHigher *int         `bson:"proportion_of_students_gaining_intended_award_or_higher,omitempty"`
+	GainedLowerAward             *int         `bson:"proportion_of_students_gained_lower_award,omitempty"`
+	LeavingCourse                *int         `bson:"proportion_of_students_leaving_course,omitempty"`
 	Subject                      *Subject     `bson:"subject,omitempty"`
 	Unavailable                  *Unavailable `bson:"unavailable,omitempty"`
 }
 
+// DegreeClass represents the degree class statistical data for course (or subject)
+type DegreeClass struct {
+	AggregationLevel   int          `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents   *int         `bson:"number_of_students,omitempty"`
+	Distinction        *int         `bson:"proportion_of_students_gaining_distinction,omitempty"`
+	FirstClass         *int         `bson:"proportion_of_students_gaining_first_class,omitempty"`
+	LowerSecondClass   *int         `bson:"proportion_of_students_gaining_lower_second_class,omitempty"`
+	Merit              *int         `bson:"proportion_of_students_gaining_merit,omitempty"`
+	OrdinaryDegree     *int         `bson:"proportion_of_students_gaining_ordinary_degree,omitempty"`
+	OtherHonoursDegree *int         `bson:"proportion_of_students_gaining_other_honours_degree,omitempty"`
+	Pass               *int         `bson:"proportion_of_students_gaining_pass,omitempty"`
+	UnclassifiedDegree *int         `bson:"proportion_of_students_gaining_unclassified_degree,omitempty"`
+	UpperSecondClass   *int         `bson:"proportion_of_students_gaining_upper_second_class,omitempty"`
+	Subject            *Subject     `bson:"subject,omitempty"`
+	Unavailable        *Unavailable `bson:"unavailable,omitempty"`
+}
+
 // Employment represents the employment statistical data for course (or subject)
 type Employment struct {
 	AggregationLevel           int          `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents           int          `bson:"number_of_students,omitempty"`
-	AssumedToBeUnemployed      int          `bson:"proportion_of_students_assumed_to_be_unemployed,omitempty"`
-	InStudy                    int          `bson:"proportion_of_students_in_study,omitempty"`
-	InWork                     int          `bson:"proportion_of_students_in_work,omitempty"`
-	InWorkAndStudy             int          `bson:"proportion_of_students_in_work_and_study,omitempty"`
-	InWorkOrStudy              int          `bson:"proportion_of_students_in_work_or_study,omitempty"`
-	NotAvailableForWorkOrStudy int          `bson:"proportion_of_students_who_are_not_available_for_work_or_study,omitempty"`
-	ResponseRate               int          `bson:"response_rate,omitempty"`
+	NumberOfStudents           *int         `bson:"number_of_students,omitempty"`
+	AssumedToBeUnemployed      *int         `bson:"proportion_of_students_assumed_to_be_unemployed,omitempty"`
+	InStudy                    *int         `bson:"proportion_of_students_in_study,omitempty"`
+	InWork                     *int         `bson:"proportion_of_students_in_work,omitempty"`
+	InWorkAndStudy             *int         `bson:"proportion_of_students_in_work_and_study,omitempty"`
+	InWorkOrStudy              *int         `bson:"proportion_of_students_in_work_or_study,omitempty"`
+	NotAvailableForWorkOrStudy *int         `bson:"proportion_of_students_who_are_not_available_for_work_or_study,omitempty"`
+	ResponseRate               *int         `bson:"response_rate,omitempty"`
 	Subject                    *Subject     `bson:"subject,omitempty"`
 	Unavailable                *Unavailable `bson:"unavailable,omitempty"`
 }
 
+// Entry represents the entry qualifications statistical data for course (or subject)
+type Entry struct {
+	AggregationLevel          int          `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents          *int         `bson:"number_of_students,omitempty"`
+	AccessCourse              *int         `bson:"proportion_of_students_with_access_course,omitempty"`
+	ALevel                    *int         `bson:"proportion_of_students_with_a_level,omitempty"`
+	Baccalaureate             *int         `bson:"proportion_of_students_with_baccalaureate,omitempty"`
+	Degree                    *int         `bson:"proportion_of_students_with_degree,omitempty"`
+	Foundation                *int         `bson:"proportion_of_students_with_foundation,omitempty"`
+	NoQualifications          *int         `bson:"proportion_of_students_with_no_qualifications,omitempty"`
+	OtherQualifications       *int         `bson:"proportion_of_students_with_other_qualifications,omitempty"`
+	OtherHigherEducationQuals *int         `bson:"proportion_of_students_with_another_higher_education_qualifications,omitempty"`
+	Subject                   *Subject     `bson:"subject,omitempty"`
+	Unavailable               *Unavailable `bson:"unavailable,omitempty"`
+}
+
 // JobList represents the job list statistical data for course
 type JobList struct {
 	Items       []*SubjectItem `bson:"items,omitempty"`
@@ -57,8 +98,8 @@ type JobList struct {
 type SubjectItem struct {
 	AggregationLevel int          `bson:"aggregation_level,omitempty"` // enum
 	List             []Job        `bson:"list,omitempty"`
-	NumberOfStudents int          `bson:"number_of_students,omitempty"`
-	ResponseRate     int          `bson:"response_rate,omitempty"`
+	NumberOfStudents *int         `bson:"number_of_students,omitempty"`
+	ResponseRate     *int         `bson:"response_rate,omitempty"`
 	Subject          *Subject     `bson:"subject,omitempty"`
 	Unavailable      *Unavailable `bson:"unavailable,omitempty"`
 }
@@ -67,58 +108,95 @@ type SubjectItem struct {
 type Job struct {
 	Job                  string   `bson:"job"`
 	Order                int      `bson:"order,omitempty"`
-	PercentageOfStudents int      `bson:"percentage_of_students"`
+	PercentageOfStudents *int     `bson:"percentage_of_students,omitempty"`
 	Subject              *Subject `bson:"subject,omitempty"`
 }
 
 // JobType represents the job type statistical data for course (or subject)
 type JobType struct {
 	AggregationLevel                int          `bson:"aggregation_level,omitempty"` // enum
-	NumberOfStudents                int          `bson:"number_of_students,omitempty"`
-	ProfessionalOrManagerialJobs    int          `bson:"proportion_of_students_in_professional_or_managerial_jobs,omitempty"`
-	NonProfessionalOrManagerialJobs int          `bson:"proportion_of_students_in_non_professional_or_managerial_jobs,omitempty"`
-	UnknownProfessions              int          `bson:"proportion_of_students_in_unknown_professions,omitempty"`
-	ResponseRate                    int          `bson:"response_rate,omitempty"`
+	NumberOfStudents                *int         `bson:"number_of_students,omitempty"`
+	ProfessionalOrManagerialJobs    *int         `bson:"proportion_of_students_in_professional_or_managerial_jobs,omitempty"`
+	NonProfessionalOrManagerialJobs *int         `bson:"proportion_of_students_in_non_professional_or_managerial_jobs,omitempty"`
+	UnknownProfessions              *int         `bson:"proportion_of_students_in_unknown_professions,omitempty"`
+	ResponseRate                    *int         `bson:"response_rate,omitempty"`
 	Subject                         *Subject     `bson:"subject,omitempty"`
 	Unavailable                     *Unavailable `bson:"unavailable,omitempty"`
 }
 
 // JobOrder represents statistical data of the number of students in a job after taking course (or subject)
 type JobOrder struct {
+	KISCourseID          string   `bson:"kis_course_id"`
+	KISMode              string   `bson:"kis_mode"`
+	PublicUKPRN          string   `bson:"public_ukprn"`
 	Order                int      `bson:"order"`
 	Job                  string   `bson:"job"`
-	PercentageOfStudents int      `bson:"percentage_of_students"`
+	PercentageOfStudents *int     `bson:"percentage_of_students,omitempty"`
 	Subject              *Subject `bson:"subject, omitempty"`
 }
 
 // LEO represents the LEO statistical data for course (or subject)
 type LEO struct {
 	AggregationLevel    int          `bson:"aggregation_level,omitempty"` // enum
-	HigherQuartileRange int          `bson:"higher_quartile_range,omitempty"`
-	LowerQuartileRange  int          `bson:"lower_quartile_range,omitempty"`
-	Median              int          `bson:"median,omitempty"`
-	NumberOfGraduates   int          `bson:"number_of_graduates,omitempty"`
+	HigherQuartileRange *int         `bson:"higher_quartile_range,omitempty"`
+	LowerQuartileRange  *int         `bson:"lower_quartile_range,omitempty"`
+	Median              *int         `bson:"median,omitempty"`
+	NumberOfGraduates   *int         `bson:"number_of_graduates,omitempty"`
 	Subject             *Subject     `bson:"subject,omitempty"`
 	Unavailable         *Unavailable `bson:"unavailable,omitempty"`
 }
 
+// NSS represents the National Student Survey, or NHS National Student Survey,
+// statistical data for course (or subject)
+type NSS struct {
+	AggregationLevel int          `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents *int         `bson:"number_of_students,omitempty"`
+	ResponseRate     *int         `bson:"response_rate,omitempty"`
+	Questions        []*Question  `bson:"questions,omitempty"`
+	Subject          *Subject     `bson:"subject,omitempty"`
+	Unavailable      *Unavailable `bson:"unavailable,omitempty"`
+}
+
+// Question represents the proportion of students agreeing with a survey question
+type Question struct {
+	Number                    int    `bson:"question_number,omitempty"`
+	Question                  string `bson:"question,omitempty"`
+	ProportionOfStudentsAgree int    `bson:"proportion_of_students_agree_or_strongly_agree"`
+}
+
 // Salary represents the salary statistical data for course (or subject)
 type Salary struct {
 	AggregationLevel    int          `bson:"aggregation_level,omitempty"` // enum
-	HigherQuartileRange int          `bson:"higher_quartile_range,omitempty"`
-	LowerQuartileRange  int          `bson:"lower_quartile_range,omitempty"`
-	Median              int          `bson:"median,omitempty"`
-	NumberOfGraduates   int          `bson:"number_of_graduates,omitempty"`
-	ResponseRate        int          `bson:"response_rate,omitempty"`
+	HigherQuartileRange *int         `bson:"higher_quartile_range,omitempty"`
+	LowerQuartileRange  *int         `bson:"lower_quartile_range,omitempty"`
+	Median              *int         `bson:"median,omitempty"`
+	NumberOfGraduates   *int         `bson:"number_of_graduates,omitempty"`
+	ResponseRate        *int         `bson:"response_rate,omitempty"`
 	Subject             *Subject     `bson:"subject,omitempty"`
 	Unavailable         *Unavailable `bson:"unavailable,omitempty"`
 }
 
+// Tariff represents the entry tariff points statistical data for course (or subject)
+type Tariff struct {
+	AggregationLevel int           `bson:"aggregation_level,omitempty"` // enum
+	NumberOfStudents *int          `bson:"number_of_students,omitempty"`
+	Tariffs          []*TariffBand `bson:"tariffs,omitempty"`
+	Subject          *Subject      `bson:"subject,omitempty"`
+	Unavailable      *Unavailable  `bson:"unavailable,omitempty"`
+}
+
+// TariffBand represents the proportion of entrants with tariff points in a band
+type TariffBand struct {
+	Code                 string `bson:"code"`
+	Description          string `bson:"description"`
+	ProportionOfEntrants int    `bson:"proportion_of_entrants"`
+}
+
 // Stats contains a set of values for different statistical measurements of a dataset
 type Stats struct {
-	LowerQuartile int `bson:"lower_quartile_salary,omitempty"`             // LQ
-	Median        int `bson:"median,omitempty"`                            // MED
-	UpperQuartile int `bson:"upper_quartile_salary_for_subject,omitempty"` // UQ
+	LowerQuartile *int `bson:"lower_quartile_salary,omitempty"`             // LQ
+	Median        *int `bson:"median,omitempty"`                            // MED
+	UpperQuartile *int `bson:"upper_quartile_salary_for_subject,omitempty"` // UQ
 }
 
 // Subject represents an object referring to subject code and name
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/internal/golden"
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// goldenAPIURL is the api url the courses of the golden file link to
+const goldenAPIURL = "https://localhost:10000"
+
+// setUp points the builder at the csv files in dir and the release of the
+// golden file, linking to goldenAPIURL, with a memory store seeded with the
+// documents the other builders load. The returned function closes the source.
+func setUp(t *testing.T, dir string) func() {
+	t.Helper()
+
+	var err error
+	if src, err = source.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	release = "2019"
+	configure()
+	if links, err = cfg.Links(goldenAPIURL); err != nil {
+		t.Fatal(err)
+	}
+
+	st = store.NewMemory()
+	golden.Seed(t, st, "institutions", "institutions")
+	golden.Seed(t, st, "courses", "locations", "qualifications", "subjects")
+	golden.Seed(t, st, "statistics", "common", "continuation", "degree-class", "employment", "entry", "job-list", "job-type", "leo", "nhs-nss", "nss", "salary", "tariff")
+
+	return func() { src.Close() }
+}
+
+// TestGolden builds the courses of the fixture KISCOURSE file in
+// testdata/golden, looking up the documents the other builders load from
+// their golden files, seeded from testdata/store, and compares them with
+// testdata/golden/courses.json. The courses are built serially and by a pool
+// of workers writing a course at a time, both of which must match. Run with
+// -update to rewrite it after a deliberate change to a mapping.
+func TestGolden(t *testing.T) {
+	for _, run := range []struct {
+		name      string
+		workers   int
+		batchSize int
+	}{
+		{"serial", 1, 1000},
+		{"workers", 4, 1},
+	} {
+		t.Run(run.name, func(t *testing.T) {
+			defer setUp(t, filepath.Join("testdata", "golden"))()
+			workers, batchSize = run.workers, run.batchSize
+
+			created, err := createCourses(courseFileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var courses []map[string]interface{}
+			if err = st.Find(database, stagingCollection, nil, &courses); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(courses) != created {
+				t.Fatalf("expected %d courses in staging, got %d", created, len(courses))
+			}
+
+			golden.Check(t, filepath.Join("testdata", "golden", "courses.json"), courses)
+		})
+	}
+}
+
+// TestDuplicateCourseID checks a course csv listing a course twice fails the
+// build rather than writing two courses with one id
+func TestDuplicateCourseID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "courses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "KISCOURSE.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The header and first course, then every course
+	lines := strings.SplitAfter(string(fixture), "\n")
+	duplicated := lines[0] + lines[1] + strings.Join(lines[1:], "")
+	if err = ioutil.WriteFile(filepath.Join(dir, "KISCOURSE.csv"), []byte(duplicated), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer setUp(t, dir)()
+	workers, batchSize = 4, 1000
+
+	if _, err = createCourses(courseFileName); err == nil || !strings.Contains(err.Error(), "same id") {
+		t.Errorf("expected duplicate course id error, got %v", err)
+	}
+}
+
+// TestRelink rewrites the links of the courses of the golden file and checks
+// nothing else about them changes
+func TestRelink(t *testing.T) {
+	goldenFile, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "courses.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var courses []map[string]interface{}
+	if err = json.Unmarshal(goldenFile, &courses); err != nil {
+		t.Fatal(err)
+	}
+
+	defer setUp(t, filepath.Join("testdata", "golden"))()
+	for _, course := range courses {
+		if err = st.Insert(database, collection, course); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if links, err = cfg.Links("https://api.example.com/v1/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = relinkCourses(); err != nil {
+		t.Fatal(err)
+	}
+
+	var relinked []map[string]interface{}
+	if err = st.Find(database, collection, nil, &relinked); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.MarshalIndent(relinked, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Replace(string(goldenFile), goldenAPIURL, "https://api.example.com/v1", -1)
+	if string(got)+"\n" != expected {
+		t.Errorf("expected only the links of courses to change, got:\n%s", got)
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// indexes of the courses collection: a course is unique by institution, course
+// and mode within its release, which also lets the courses of a release be read
+// without a collection scan, and is found near a point by its teaching location
+var indexes = []store.Index{
+	{Key: []string{"release", "institution.public_ukprn", "institution.ukprn", "kis_course_id", "mode.code"}, Unique: true},
+	{Key: []string{"$2dsphere:location.point"}},
+}
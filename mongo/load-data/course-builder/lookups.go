@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+
+	generalData "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+	institutionData "github.com/ofs/alpha-scripts/mongo/load-data/institution-builder/data"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/statistics"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// courseKey identifies a course by the institutions it belongs to, its kis
+// course id and its mode
+type courseKey struct {
+	ukprn, publicUKPRN, kisCourseID, kisMode string
+}
+
+// lookups holds the documents the other builders load which courses are
+// joined with. Each collection is read once, before any course is built.
+type lookups struct {
+	institutions       map[string]*institutionData.Institution // by ukprn
+	publicInstitutions map[string]*institutionData.Institution // by public ukprn
+	courseLocations    map[courseKey]*generalData.Location
+	qualifications     map[string]*generalData.Qualification // by code
+	statistics         *statistics.Index
+}
+
+// loadLookups reads the institutions, course locations, qualifications and
+// statistics of the release
+func loadLookups() (*lookups, error) {
+	l := &lookups{
+		institutions:       make(map[string]*institutionData.Institution),
+		publicInstitutions: make(map[string]*institutionData.Institution),
+		courseLocations:    make(map[courseKey]*generalData.Location),
+		qualifications:     make(map[string]*generalData.Qualification),
+	}
+
+	// As with a query, the first institution with a ukprn or public ukprn is used
+	var institutions []*institutionData.Institution
+	if err := findConfigured("institutions", "institutions", store.Key{"release": release}, &institutions); err != nil {
+		return nil, err
+	}
+
+	for _, institution := range institutions {
+		if _, ok := l.institutions[institution.UKPRN]; !ok {
+			l.institutions[institution.UKPRN] = institution
+		}
+
+		if _, ok := l.publicInstitutions[institution.PublicUKPRN]; !ok {
+			l.publicInstitutions[institution.PublicUKPRN] = institution
+		}
+	}
+
+	// A course's location is the first of its locations with a location id
+	var locations []*generalData.Location
+	if err := findConfigured("courses", "locations", store.Key{"release": release}, &locations); err != nil {
+		return nil, err
+	}
+
+	for _, location := range locations {
+		key := courseKey{location.UKPRN, location.PublicUKPRN, location.KISCourseID, location.KISMode}
+		if _, ok := l.courseLocations[key]; !ok && location.ID != "" {
+			l.courseLocations[key] = location
+		}
+	}
+
+	// Qualifications are reference data shared by every release
+	var qualifications []*generalData.Qualification
+	if err := findConfigured("courses", "qualifications", nil, &qualifications); err != nil {
+		return nil, err
+	}
+
+	for _, qualification := range qualifications {
+		if _, ok := l.qualifications[qualification.Code]; !ok {
+			l.qualifications[qualification.Code] = qualification
+		}
+	}
+
+	var err error
+	if l.statistics, err = statistics.Load(st, cfg, release); err != nil {
+		return nil, err
+	}
+
+	log.Info("read documents courses are joined with", log.Data{"institutions": len(institutions), "course_locations": len(l.courseLocations), "qualifications": len(l.qualifications)})
+
+	return l, nil
+}
+
+// findConfigured decodes the documents matching the key from a collection
+// another builder loads, given the default names of it and the database
+// holding it
+func findConfigured(db, name string, key store.Key, results interface{}) error {
+	if err := st.Find(cfg.Database(db), cfg.Collection(db, name), key, results); err != nil {
+		log.ErrorC("failed to read collection", err, log.Data{"database": db, "collection": name})
+		return err
+	}
+
+	return nil
+}
+
+// institution returns the institution of the release with the ukprn
+func (l *lookups) institution(ukprn string) (*institutionData.Institution, error) {
+	institution, ok := l.institutions[ukprn]
+	if !ok {
+		return nil, fmt.Errorf("no institution with ukprn %s", ukprn)
+	}
+
+	return institution, nil
+}
+
+// publicInstitution returns the institution of the release with the public ukprn
+func (l *lookups) publicInstitution(publicUKPRN string) (*institutionData.Institution, error) {
+	institution, ok := l.publicInstitutions[publicUKPRN]
+	if !ok {
+		return nil, fmt.Errorf("no institution with public ukprn %s", publicUKPRN)
+	}
+
+	return institution, nil
+}
+
+// courseLocation returns the location of a course
+func (l *lookups) courseLocation(ukprn, publicUKPRN, kisCourseID, kisMode string) (*generalData.Location, error) {
+	location, ok := l.courseLocations[courseKey{ukprn, publicUKPRN, kisCourseID, kisMode}]
+	if !ok {
+		return nil, fmt.Errorf("no location id for course %s mode %s of %s", kisCourseID, kisMode, publicUKPRN)
+	}
+
+	return location, nil
+}
+
+// qualification returns the qualification with the code, or nil if there is
+// none as some are added by manualQualificationLookup
+func (l *lookups) qualification(code string) *generalData.Qualification {
+	return l.qualifications[code]
+}
@@ -6,56 +6,186 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
+	"runtime"
+	"strings"
 
-	generalData "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
 	institutionData "github.com/ofs/alpha-scripts/mongo/load-data/institution-builder/data"
 	uuid "github.com/satori/go.uuid"
 
 	"github.com/ONSdigital/go-ns/log"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
-	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/statistics"
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+	"github.com/ofs/alpha-scripts/mongo/load-data/validation"
 )
 
 var (
 	mongoURI string
-
-	database             = "courses"
-	collection           = "courses"
-	relativeFileLocation = "../files/"
-	courseFileName       = "KISCOURSE"
-	fileExtension        = ".csv"
+	storeDir string
+	st       store.Store
+
+	configFile     string
+	cfg            *config.Config
+	database       = "courses"
+	collection     = "courses"
+	sourceLocation = "../files/"
+	rollback       bool
+	relink         bool
+	validateOnly   bool
+	release        string
+	batchSize      = 1000
+	workers        = runtime.NumCPU()
+	courseFileName = "KISCOURSE"
+	fileExtension  = ".csv"
+
+	// Courses are built in a staging collection, named after the configured
+	// collection, so the API keeps reading the live courses until every course
+	// has been built
+	stagingCollection string
+
+	apiURL string
+	links  *config.Links
+
+	src *source.Source
 )
 
 func main() {
 	flag.StringVar(&mongoURI, "mongo-uri", mongoURI, "mongoDB URI")
-	flag.StringVar(&relativeFileLocation, "relative-file-location", relativeFileLocation, "relative location of files")
+	flag.StringVar(&storeDir, "store-dir", storeDir, "directory to write documents to as JSON lines files instead of mongo")
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the csv files, databases and collections, names not in it keep their defaults")
+	flag.StringVar(&sourceLocation, "source", sourceLocation, "directory or zip archive holding the csv files")
+	flag.StringVar(&sourceLocation, "relative-file-location", sourceLocation, "deprecated, use -source")
+	flag.StringVar(&release, "release", release, "HESA release the data belongs to, e.g. 2018, loading a release leaves others in place")
+	flag.BoolVar(&rollback, "rollback", rollback, "restore the previous generation of courses instead of loading data")
+	flag.BoolVar(&relink, "relink", relink, "rewrite the links of every stored course from the api url and link templates instead of loading data")
+	flag.StringVar(&apiURL, "api-url", apiURL, "base url of the public api links are built from, overrides api_url in the config file")
+	flag.IntVar(&batchSize, "batch-size", batchSize, "number of courses written to mongo in each bulk write")
+	flag.IntVar(&workers, "workers", workers, "number of courses built at once, defaults to the number of CPUs")
+	flag.BoolVar(&validateOnly, "validate-only", validateOnly, "check the course csv file and report problems without connecting to mongo")
 	flag.Parse()
 
-	if mongoURI == "" {
-		log.Error(errors.New("missing mongo-uri flag"), nil)
+	var err error
+	if cfg, err = config.Load(configFile); err != nil {
+		log.ErrorC("failed to read config file", err, log.Data{"config": configFile})
+		os.Exit(1)
+	}
+	configure()
+
+	if src, err = source.Open(sourceLocation); err != nil {
+		log.ErrorC("failed to open source of csv files", err, log.Data{"source": sourceLocation})
+		os.Exit(1)
+	}
+	defer src.Close()
+
+	log.Info("reading csv files", src.LogData())
+
+	if validateOnly {
+		results := validate()
+		if err := validation.WriteReport(os.Stdout, src, results); err != nil {
+			log.ErrorC("failed to write validation report", err, nil)
+		}
+
+		for _, result := range results {
+			if result.Status != validation.StatusValid {
+				log.Error(errors.New("Unsuccessful validation of course data"), log.Data{"file": result.File, "status": result.Status})
+				os.Exit(1)
+			}
+		}
+
+		log.Info("Successful validation of course data", src.LogData())
+		return
+	}
+
+	if mongoURI == "" && storeDir == "" {
+		log.Error(errors.New("missing mongo-uri or store-dir flag"), nil)
+		os.Exit(1)
+	}
+
+	if batchSize < 1 {
+		log.Error(errors.New("batch-size flag must be at least 1"), log.Data{"batch_size": batchSize})
+		os.Exit(1)
+	}
+
+	if !rollback {
+		if links, err = cfg.Links(apiURL); err != nil {
+			log.ErrorC("failed to read api url and link templates", err, nil)
+			os.Exit(1)
+		}
+	}
+
+	if st, err = store.Open(mongoURI, storeDir); err != nil {
+		log.ErrorC("failed to open store", err, log.Data{"store_dir": storeDir})
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	if rollback {
+		if err := st.Rollback(database, collection); err != nil {
+			log.ErrorC("Unsuccessfully attempted to roll back course data", err, nil)
+			os.Exit(1)
+		}
+
+		log.Info("Successfully rolled back course data", nil)
+		return
+	}
+
+	if relink {
+		if err := relinkCourses(); err != nil {
+			log.ErrorC("Unsuccessfully attempted to relink course data", err, nil)
+			os.Exit(1)
+		}
+
+		log.Info("Successfully relinked course data", nil)
+		return
+	}
+
+	if release == "" {
+		log.Error(errors.New("missing release flag"), nil)
+		os.Exit(1)
+	}
+
+	if workers < 1 {
+		log.Error(errors.New("workers flag must be at least 1"), log.Data{"workers": workers})
+		os.Exit(1)
+	}
+
+	if err := store.PrepareStaging(st, database, collection, release, indexes); err != nil {
+		os.Exit(1)
+	}
+
+	created, err := createCourses(courseFileName)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	count, err := st.Count(database, stagingCollection, store.Key{"release": release})
+	if err != nil {
+		log.ErrorC("failed to count courses in staging collection", err, nil)
 		os.Exit(1)
 	}
 
-	if err := dropCollection(); err != nil {
+	if count == 0 || count != created {
+		log.Error(errors.New("staging collection failed validation, live collection has not been replaced"), log.Data{"staging_count": count, "created_count": created})
 		os.Exit(1)
 	}
 
-	if err := createCourses(courseFileName); err != nil {
+	if err := st.Swap(database, collection); err != nil {
+		log.ErrorC("failed to swap staging collection into place", err, nil)
 		os.Exit(1)
 	}
 
-	log.Info("Successfully loaded data", nil)
+	logData := src.LogData()
+	logData["release"] = release
+	log.Info("Successfully loaded data", logData)
 }
 
-func createCourses(fileName string) error {
-	csvFile, err := os.Open(relativeFileLocation + fileName + fileExtension)
+func createCourses(fileName string) (int, error) {
+	csvFile, err := src.Open(fileName + fileExtension)
 	if err != nil {
 		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
+		return 0, err
 	}
 	csvReader := csv.NewReader(bufio.NewReader(csvFile))
 
@@ -63,253 +193,260 @@ func createCourses(fileName string) error {
 	_, err = csvReader.Read()
 	if err != nil {
 		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+		return 0, err
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
+	// Everything a course is joined with is read up front, so building a
+	// course needs no queries
+	lookup, err := loadLookups()
+	if err != nil {
+		return 0, err
+	}
 
-		distance, err := distanceLearningCodeToLabel(line[6])
-		if err != nil {
-			log.Error(err, log.Data{"func": "distanceLearningCodeToLabel", "line_count": count, "csv_line": line})
-			return err
-		}
+	count, err := buildCourses(csvReader, lookup)
+	if err != nil {
+		return count, err
+	}
 
-		var honours bool
-		if line[10] == "1" {
-			honours = true
-		}
+	log.Info("Created many course resources", log.Data{"count": count})
 
-		length, err := lengthCodeToLabel(line[25])
-		if err != nil {
-			log.Error(err, log.Data{"func": "lengthCodeToLabel", "line_count": count, "csv_line": line})
-			return err
-		}
+	return count, nil
+}
 
-		mode, err := modeCodeToLabel(line[17])
-		if err != nil {
-			log.Error(err, log.Data{"func": "modeCodeToLabel", "line_count": count, "csv_line": line})
-			return err
-		}
+// buildCourse builds the course of a KISCOURSE row, joining it with the
+// institutions, location, qualification and statistics it refers to. It only
+// reads the lookups, so rows may be built concurrently.
+func buildCourse(lookup *lookups, line []string, lineCount int) (*data.Course, error) {
+	course, err := mapCourse(line)
+	if err != nil {
+		log.Error(err, log.Data{"func": "mapCourse", "line_count": lineCount, "csv_line": line})
+		return nil, err
+	}
 
-		sandwichYear, err := availabilityCodeToDescription(line[26])
-		if err != nil {
-			log.Error(err, log.Data{"func": "sandwich-availabilityCodeToDescription", "line_count": count, "csv_line": line})
-			return err
-		}
+	institution, err := lookup.institution(line[1])
+	if err != nil {
+		log.Error(err, log.Data{"func": "institution", "line_count": lineCount, "ukprn": line[1]})
+		return nil, err
+	}
 
-		yearAbroad, err := availabilityCodeToDescription(line[33])
-		if err != nil {
-			log.Error(err, log.Data{"func": "year-abroad-availabilityCodeToDescription", "line_count": count, "csv_line": line})
-			return err
-		}
+	publicInstitution, err := lookup.publicInstitution(line[0])
+	if err != nil {
+		log.Error(err, log.Data{"func": "publicInstitution", "line_count": lineCount, "public_ukprn": line[0]})
+		return nil, err
+	}
 
-		institution, err := getInstitution("ukprn", line[1])
-		if err != nil {
-			log.Error(err, log.Data{"func": "getInstitution", "line_count": count, "ukprn": line[1]})
-			return err
-		}
+	var missingLocationID bool
+	courseLocationID := ""
+	courseLocation, err := lookup.courseLocation(line[1], line[0], line[16], line[17])
+	if err != nil {
+		log.Error(err, log.Data{"func": "courseLocation", "line_count": lineCount, "public_ukprn": line[0], "course_id": line[16], "course_mode": line[17]})
+		missingLocationID = true
+	} else {
+		courseLocationID = courseLocation.ID
+	}
 
-		publicInstitution, err := getInstitution("public_ukprn", line[0])
-		if err != nil {
-			log.Error(err, log.Data{"func": "getInstitution", "line_count": count, "public_ukprn": line[0]})
-			return err
-		}
+	// Find teaching location based on courseLocation.ID within locations array inside either publicInstitution or Institution resource
+	teachingLocation, err := findTeachingLocation(missingLocationID, institution.Locations, publicInstitution.Locations, courseLocationID)
+	if err != nil {
+		log.Error(err, log.Data{"func": "findTeachingLocation", "line_count": lineCount, "public_ukprn": line[0], "course_id": line[16], "course_mode": line[17]})
+	}
 
-		var missingLocationID bool
-		courseLocationID := ""
-		courseLocation, err := getCourseLocation(line[1], line[0], line[16], line[17])
-		if err != nil {
-			log.Error(err, log.Data{"func": "getLocationIDObject", "line_count": count, "public_ukprn": line[0], "course_id": line[16], "course_mode": line[17]})
-			missingLocationID = true
-		} else {
-			courseLocationID = courseLocation.ID
-		}
+	qualification := lookup.qualification(line[34])
 
-		// Find teaching location based on courseLocation.ID within locations array inside either publicInstitution or Institution resource
-		teachingLocation, err := findTeachingLocation(missingLocationID, institution.Locations, publicInstitution.Locations, courseLocationID)
-		if err != nil {
-			log.Error(err, log.Data{"func": "findTeachingLocation", "line_count": count, "public_ukprn": line[0], "course_id": line[16], "course_mode": line[17]})
+	course.ID = courseID(line[0], line[1], line[16], line[17])
+	course.Release = release
+	course.Country = &data.Country{
+		Code: institution.Country.Code,
+		Name: institution.Country.Name,
+	}
+	course.Institution.UKPRNName = institution.Name
+	course.Institution.PublicUKPRNName = publicInstitution.Name
+	course.Links.Institution = links.Institution(institution.PublicUKPRN)
+	course.Links.Self = links.Course(institution.PublicUKPRN, line[16], line[17])
+
+	if teachingLocation != nil && teachingLocation.Latitude != "" {
+		course.Location.Latitude = teachingLocation.Latitude
+		course.Location.Longitude = teachingLocation.Longitude
+		course.Location.Point = (*data.Point)(teachingLocation.Point)
+		course.Location.Name = &data.Language{
+			English: teachingLocation.Name.English,
+			Welsh:   teachingLocation.Name.Welsh,
 		}
 
-		qualification, err := getQualification(line[34])
-		if err != nil {
-			log.Error(err, log.Data{"func": "getQualification", "line_count": count, "qualification_code": line[34]})
-			return err
-		}
+		if teachingLocation.Links != nil {
+			if teachingLocation.Links.Accommodation != nil {
+				course.Links.Accommodation = &data.Language{
+					English: teachingLocation.Links.Accommodation.English,
+					Welsh:   teachingLocation.Links.Accommodation.Welsh,
+				}
+			}
 
-		id, err := uuid.NewV4()
-		if err != nil {
-			log.Error(err, log.Data{"func": "uuid.NewV4", "line_count": count})
-			return err
+			if teachingLocation.Links.StudentUnion != nil {
+				course.Links.StudentUnion = &data.Language{
+					English: teachingLocation.Links.StudentUnion.English,
+					Welsh:   teachingLocation.Links.StudentUnion.Welsh,
+				}
+			}
 		}
+	}
 
-		course := &data.Course{
-			ApplicationProvider: line[32],
-			Country: &data.Country{
-				Code: institution.Country.Code,
-				Name: institution.Country.Name,
-			},
-			DistanceLearning: &data.DistanceLearning{
-				Code:  line[6],
-				Label: distance,
-			},
-			Foundation: line[9],
-			Honours:    honours,
-			ID:         id.String(),
-			Institution: &data.InstitutionObject{
-				UKPRNName:       institution.Name,
-				UKPRN:           line[1],
-				PublicUKPRNName: publicInstitution.Name,
-				PublicUKPRN:     line[0],
-			},
-			KISCourseID: line[16],
-			Length: &data.LengthObject{
-				Code:  line[25],
-				Label: length,
-			},
-			Links: &data.LinkList{
-				AssessmentMethod: &data.Language{
-					English: line[2],
-					Welsh:   line[3],
-				},
-				CoursePage: &data.Language{
-					English: line[4],
-					Welsh:   line[5],
-				},
-				EmploymentDetails: &data.Language{
-					English: line[7],
-					Welsh:   line[8],
-				},
-				FinancialSupport: &data.Language{
-					English: line[27],
-					Welsh:   line[28],
-				},
-				Institution: "https://localhost:10000/institutions/" + institution.PublicUKPRN,
-				LearningAndTeaching: &data.Language{
-					English: line[22],
-					Welsh:   line[23],
-				},
-				Self: "https://localhost:10000/institutions/" + institution.PublicUKPRN + "/courses/" + line[16] + "/modes/" + line[17],
-			},
-			Location: &data.Location{},
-			Mode: &data.Mode{
-				Code:  line[17],
-				Label: mode,
-			},
-			SandwichYear: &data.Availability{
-				Code:  line[26],
-				Label: sandwichYear,
-			},
-			Title: &data.Language{
-				English: line[29],
-				Welsh:   line[30],
-			},
-			UCASCode: line[31],
-			YearAbroad: &data.Availability{
-				Code:  line[33],
-				Label: yearAbroad,
-			},
+	if qualification != nil {
+		course.Qualification = &data.Qualification{
+			Code:  line[34],
+			Label: qualification.Label,
+			Level: qualification.Level,
+			Name:  qualification.Name,
 		}
+	} else {
+		course.Qualification = manualQualificationLookup(line[34])
+	}
 
-		if teachingLocation != nil && teachingLocation.Latitude != "" {
-			course.Location.Latitude = teachingLocation.Latitude
-			course.Location.Longitude = teachingLocation.Longitude
-			course.Location.Name = &data.Language{
-				English: teachingLocation.Name.English,
-				Welsh:   teachingLocation.Name.Welsh,
-			}
+	stats, subject := lookup.statistics.Get(line[0], line[16], line[17], institution.Country.Code)
 
-			if teachingLocation.Links != nil {
-				if teachingLocation.Links.Accommodation != nil {
-					course.Links.Accommodation = &data.Language{
-						English: teachingLocation.Links.Accommodation.English,
-						Welsh:   teachingLocation.Links.Accommodation.Welsh,
-					}
-				}
+	course.Subject = &data.Subject{
+		Code: subject.Code,
+		Name: subject.Name,
+	}
+	course.Statistics = stats
 
-				if teachingLocation.Links.StudentUnion != nil {
-					course.Links.StudentUnion = &data.Language{
-						English: teachingLocation.Links.StudentUnion.English,
-						Welsh:   teachingLocation.Links.StudentUnion.Welsh,
-					}
-				}
-			}
-		}
+	return course, nil
+}
 
-		if qualification != nil {
-			course.Qualification = &data.Qualification{
-				Code:  line[34],
-				Label: qualification.Label,
-				Level: qualification.Level,
-				Name:  qualification.Name,
-			}
-		} else {
-			course.Qualification = manualQualificationLookup(line[34])
-		}
+// courseNamespace is the namespace course ids are derived in, the version 5
+// uuid of https://github.com/office-for-students/alpha-scripts/courses in the
+// url namespace
+var courseNamespace = uuid.Must(uuid.FromString("573a30f5-0ab3-560d-a7db-e3c8ee8a9b6c"))
+
+// courseID returns the id of a course of the release, which is the same each
+// time the release is built. Unlike the other fields of the id the release
+// does not identify the course, but every release is kept in the one
+// collection, whose _id must be unique, so the same course in two releases
+// needs two ids. A course's id therefore changes when it moves to a new
+// release, and references to it must name the release it is of.
+func courseID(publicUKPRN, ukprn, kisCourseID, kisMode string) string {
+	return uuid.NewV5(courseNamespace, strings.Join([]string{release, publicUKPRN, ukprn, kisCourseID, kisMode}, "/")).String()
+}
 
-		if line[21] != "" {
-			courseChange, err := courseChangeCodeToBool(line[21])
-			if err != nil {
-				log.Error(err, log.Data{"func": "courseChangeCodeToBool", "line_count": count, "csv_line": line})
-				return err
-			}
+// mapCourse maps the fields of a KISCOURSE row which need no lookups to a
+// course; institution, location, qualification and statistics are added by
+// createCourses
+func mapCourse(line []string) (*data.Course, error) {
+	distance, err := distanceLearningCodeToLabel(line[6])
+	if err != nil {
+		return nil, &validation.FieldError{Column: "DISTANCE", Value: line[6], Reason: err.Error()}
+	}
 
-			course.Location.Changes = courseChange
-		}
+	var honours bool
+	if line[10] == "1" {
+		honours = true
+	}
 
-		if line[24] != "" {
-			nhsFunded, err := nhsCodeToLabel(line[24])
-			if err != nil {
-				log.Error(err, log.Data{"func": "nhsCodeToLabel", "line_count": count, "csv_line": line})
-				return err
-			}
+	length, err := lengthCodeToLabel(line[25])
+	if err != nil {
+		return nil, &validation.FieldError{Column: "NUMSTAGE", Value: line[25], Reason: err.Error()}
+	}
 
-			course.NHSFunded = &data.NHSFunded{
-				Code:  line[24],
-				Label: nhsFunded,
-			}
-		}
+	mode, err := modeCodeToLabel(line[17])
+	if err != nil {
+		return nil, &validation.FieldError{Column: "KISMODE", Value: line[17], Reason: err.Error()}
+	}
 
-		stats, subject, err := statistics.Get(mongoURI, line[0], line[16], line[17], institution.Country.Code)
-		if err != nil {
-			log.Error(err, log.Data{"func": "statistics.Get", "line_count": count, "csv_line": line})
-			return err
-		}
+	sandwichYear, err := availabilityCodeToDescription(line[26])
+	if err != nil {
+		return nil, &validation.FieldError{Column: "SANDWICH", Value: line[26], Reason: err.Error()}
+	}
 
-		course.Subject = &data.Subject{
-			Code: subject.Code,
-			Name: subject.Name,
-		}
-		course.Statistics = stats
+	yearAbroad, err := availabilityCodeToDescription(line[33])
+	if err != nil {
+		return nil, &validation.FieldError{Column: "YEARABROAD", Value: line[33], Reason: err.Error()}
+	}
+
+	course := &data.Course{
+		ApplicationProvider: line[32],
+		DistanceLearning: &data.DistanceLearning{
+			Code:  line[6],
+			Label: distance,
+		},
+		Foundation: line[9],
+		Honours:    honours,
+		Institution: &data.InstitutionObject{
+			UKPRN:       line[1],
+			PublicUKPRN: line[0],
+		},
+		KISCourseID: line[16],
+		Length: &data.LengthObject{
+			Code:  line[25],
+			Label: length,
+		},
+		Links: &data.LinkList{
+			AssessmentMethod: &data.Language{
+				English: line[2],
+				Welsh:   line[3],
+			},
+			CoursePage: &data.Language{
+				English: line[4],
+				Welsh:   line[5],
+			},
+			EmploymentDetails: &data.Language{
+				English: line[7],
+				Welsh:   line[8],
+			},
+			FinancialSupport: &data.Language{
+				English: line[27],
+				Welsh:   line[28],
+			},
+			LearningAndTeaching: &data.Language{
+				English: line[22],
+				Welsh:   line[23],
+			},
+		},
+		Location: &data.Location{},
+		Mode: &data.Mode{
+			Code:  line[17],
+			Label: mode,
+		},
+		SandwichYear: &data.Availability{
+			Code:  line[26],
+			Label: sandwichYear,
+		},
+		Title: &data.Language{
+			English: line[29],
+			Welsh:   line[30],
+		},
+		UCASCode: line[31],
+		YearAbroad: &data.Availability{
+			Code:  line[33],
+			Label: yearAbroad,
+		},
+	}
 
-		// Missing title for ucas code 'A16-H09'
-		if line[31] == "A16-H09" {
-			course.Title.English = "Law"
+	if line[21] != "" {
+		courseChange, err := courseChangeCodeToBool(line[21])
+		if err != nil {
+			return nil, &validation.FieldError{Column: "LOCCHNGE", Value: line[21], Reason: err.Error()}
 		}
 
-		if err := addResource(course); err != nil {
-			log.ErrorC("failed to add course resource", err, log.Data{"line_count": count, "course_resource": course})
-			return err
+		course.Location.Changes = courseChange
+	}
+
+	if line[24] != "" {
+		nhsFunded, err := nhsCodeToLabel(line[24])
+		if err != nil {
+			return nil, &validation.FieldError{Column: "NHS", Value: line[24], Reason: err.Error()}
 		}
 
-		count++
-		if count%1000 == 0 {
-			log.Info(fmt.Sprintf("Progress: %v", count), nil)
+		course.NHSFunded = &data.NHSFunded{
+			Code:  line[24],
+			Label: nhsFunded,
 		}
 	}
 
-	log.Info("Created many course resources", log.Data{"count": count})
+	// Missing title for ucas code 'A16-H09'
+	if line[31] == "A16-H09" {
+		course.Title.English = "Law"
+	}
 
-	return nil
+	return course, nil
 }
 
 func availabilityCodeToDescription(code string) (description string, err error) {
@@ -437,65 +574,31 @@ func nhsCodeToLabel(code string) (label string, err error) {
 	return
 }
 
-func addResource(course *data.Course) (err error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	if err = session.DB(database).C(collection).Insert(course); err != nil {
-		log.ErrorC("failed to create course resource", err, nil)
-		return
-	}
-
-	return
-}
-
-func getInstitution(key, value string) (institution *institutionData.Institution, err error) {
-	session, err := mgo.Dial(mongoURI)
+// addResources writes a batch of courses to the staging collection, every
+// course must be added for the load to succeed
+func addResources(courses []interface{}) error {
+	failed, err := st.BulkInsert(database, stagingCollection, courses)
 	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	if err = session.DB("institutions").C("institutions").Find(bson.M{key: value}).One(&institution); err != nil {
-		log.ErrorC("failed to find institution resource", err, nil)
-	}
-
-	return
-}
-
-func getCourseLocation(ukprn, publicUKPRN, kisCourseID, kisMode string) (locationObject *generalData.Location, err error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
+		log.ErrorC("failed to create course resources", err, log.Data{"batch_size": len(courses)})
+		return err
 	}
-	defer session.Close()
 
-	if err = session.DB("courses").C("locations").Find(bson.M{"ukprn": ukprn, "public_ukprn": publicUKPRN, "kis_course_id": kisCourseID, "kis_mode": kisMode, "id": bson.M{"$ne": ""}}).One(&locationObject); err != nil {
-		log.ErrorC("failed to find course location id resource", err, nil)
+	if len(failed) > 0 {
+		course := courses[failed[0].Index].(*data.Course)
+		log.ErrorC("failed to create course resources", failed[0].Err, log.Data{"failed": len(failed), "kis_course_id": course.KISCourseID, "kis_mode": course.Mode.Code, "public_ukprn": course.Institution.PublicUKPRN})
+		return failed[0].Err
 	}
 
-	return
+	return nil
 }
 
-func getLocation(ukprn, locID string) (teachingLocation *generalData.InstitutionLocation, err error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
+// configure names the course file, database and collection from the config
+func configure() {
+	courseFileName = cfg.File("KISCOURSE", courseFileName)
+	collection = cfg.Collection(database, collection)
+	database = cfg.Database(database)
 
-	if err = session.DB("institutions").C("locations").Find(bson.M{"ukprn": ukprn, "location_id": locID}).One(&teachingLocation); err != nil {
-		log.ErrorC("failed to find teaching location resource", err, nil)
-	}
-
-	return
+	stagingCollection = store.Staging(collection)
 }
 
 func findTeachingLocation(missingLocationID bool, ukprnLocations, publicUKPRNLocations []*institutionData.Location, locationID string) (*institutionData.Location, error) {
@@ -526,34 +629,3 @@ func findTeachingLocation(missingLocationID bool, ukprnLocations, publicUKPRNLoc
 
 	return nil, errors.New("teaching location not found in the possible locations associated with institution")
 }
-
-func getQualification(code string) (*generalData.Qualification, error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return nil, err
-	}
-	defer session.Close()
-
-	var qualification *generalData.Qualification
-	if err = session.DB(database).C("qualifications").Find(bson.M{"code": code}).One(&qualification); err != nil {
-		log.ErrorC("failed to find qualification resource", err, nil)
-	}
-
-	return qualification, nil
-}
-
-func dropCollection() (err error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	if _, err = session.DB(database).C(collection).RemoveAll(nil); err != nil {
-		return
-	}
-
-	return
-}
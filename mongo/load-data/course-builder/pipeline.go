@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
+)
+
+// row is a line of the course csv and its position among the rows
+type row struct {
+	index int
+	line  []string
+}
+
+// built is the course built from a row, or the error which stopped it
+type built struct {
+	index  int
+	course *data.Course
+	err    error
+}
+
+// buildCourses reads the rows of the course csv, builds their courses with a
+// pool of workers and writes them to the staging collection in batches. The
+// courses are written, and progress is reported, in the order of the rows, so
+// the collection is the same whatever the number of workers. A row which fails,
+// or whose course has the id of an earlier row's, stops the load once every
+// row before it has been written.
+func buildCourses(csvReader *csv.Reader, lookup *lookups) (int, error) {
+	// Stopping early leaves no reader or worker running once it returns
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	// Each row holds a slot from being read until its course is added to a
+	// batch, so a slow worker or write holds the reader back rather than
+	// letting built courses pile up in memory
+	slots := make(chan struct{}, 2*workers)
+
+	rows := make(chan row)
+	results := make(chan built)
+
+	wg.Add(1 + workers)
+
+	go func() {
+		defer wg.Done()
+		defer close(rows)
+
+		for index := 0; ; index++ {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			line, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				log.ErrorC("encountered error reading csv", err, log.Data{"line_count": index, "csv_line": line})
+				select {
+				case results <- built{index: index, err: err}:
+				case <-done:
+				}
+				return
+			}
+
+			select {
+			case rows <- row{index: index, line: line}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for r := range rows {
+				course, err := buildCourse(lookup, r.line, r.index)
+
+				select {
+				case results <- built{index: r.index, course: course, err: err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Courses arrive in whatever order the workers finish them, so each is
+	// held until every row before it has been added
+	pending := make(map[int]built)
+	ids := make(map[string]int) // row of each course id
+	var batch []interface{}
+	next, count := 0, 0
+	for result := range results {
+		pending[result.index] = result
+
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+			<-slots
+
+			if result.err != nil {
+				return count, result.err
+			}
+
+			course := result.course
+			if first, ok := ids[course.ID]; ok {
+				err := fmt.Errorf("course %s mode %s of %s has the same id as the course on row %d", course.KISCourseID, course.Mode.Code, course.Institution.PublicUKPRN, first)
+				log.Error(err, log.Data{"line_count": result.index, "id": course.ID})
+				return count, err
+			}
+			ids[course.ID] = result.index
+
+			batch = append(batch, course)
+			if len(batch) == batchSize {
+				if err := addResources(batch); err != nil {
+					return count, err
+				}
+
+				count += len(batch)
+				batch = batch[:0]
+				log.Info(fmt.Sprintf("Progress: %v", count), nil)
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := addResources(batch); err != nil {
+			return count, err
+		}
+
+		count += len(batch)
+	}
+
+	return count, nil
+}
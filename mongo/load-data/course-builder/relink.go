@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
+	institutionData "github.com/ofs/alpha-scripts/mongo/load-data/institution-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// relinkCourses rewrites the links of the stored courses of every release
+// from the api url and link templates
+func relinkCourses() error {
+	var courses []*data.Course
+	if err := st.Find(database, collection, nil, &courses); err != nil {
+		log.ErrorC("failed to read courses", err, nil)
+		return err
+	}
+
+	// A course links to the institution of its ukprn by that institution's
+	// public ukprn, as when it was built
+	var institutions []*institutionData.Institution
+	if err := findConfigured("institutions", "institutions", nil, &institutions); err != nil {
+		return err
+	}
+
+	publicUKPRNs := make(map[[2]string]string) // keyed by release and ukprn
+	for _, institution := range institutions {
+		key := [2]string{institution.Release, institution.UKPRN}
+		if _, ok := publicUKPRNs[key]; !ok {
+			publicUKPRNs[key] = institution.PublicUKPRN
+		}
+	}
+
+	var changes []store.Change
+	for _, course := range courses {
+		publicUKPRN, ok := publicUKPRNs[[2]string{course.Release, course.Institution.UKPRN}]
+		if !ok {
+			err := fmt.Errorf("no institution with ukprn %s in release %s", course.Institution.UKPRN, course.Release)
+			log.Error(err, log.Data{"id": course.ID, "kis_course_id": course.KISCourseID})
+			return err
+		}
+
+		if course.Links == nil {
+			course.Links = &data.LinkList{}
+		}
+		course.Links.Institution = links.Institution(publicUKPRN)
+		course.Links.Self = links.Course(publicUKPRN, course.KISCourseID, course.Mode.Code)
+
+		changes = append(changes, store.Change{
+			Key:    store.Key{"_id": course.ID},
+			Update: &store.Update{Set: map[string]interface{}{"links": course.Links}},
+		})
+	}
+
+	if err := store.Rewrite(st, database, collection, indexes, changes, batchSize); err != nil {
+		return err
+	}
+
+	log.Info("Relinked courses", log.Data{"count": len(courses)})
+
+	return nil
+}
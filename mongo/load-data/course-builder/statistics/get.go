@@ -1,21 +1,18 @@
 package statistics
 
 import (
+	"errors"
 	"strconv"
-	"sync"
 
 	"github.com/ONSdigital/go-ns/log"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	"github.com/ofs/alpha-scripts/mongo/load-data/course-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
 )
 
 type statConfig struct {
 	countryCode string
-	kisCourseID string
-	kisMode     string
-	publicUKPRN string
-	uri         string
+	course      *course
 }
 
 var reason = map[int]string{
@@ -29,124 +26,235 @@ var reason = map[int]string{
 	7: "We only have this data for English universities and colleges. This is because of differences in either policy or legislation relating to this data in the other countries of the UK. **This does not reflect on the quality of the course.**",
 }
 
-// Get ...
-func Get(mongoURI, publicUKPRN, kisCourseID, kisMode, countryCode string) (*data.Statistics, *data.Subject, error) {
-	stat := statConfig{
-		countryCode: countryCode,
-		kisCourseID: kisCourseID,
-		kisMode:     kisMode,
-		publicUKPRN: publicUKPRN,
-		uri:         mongoURI,
-	}
+// courseKey identifies the course a statistics document belongs to
+type courseKey struct {
+	publicUKPRN, kisCourseID, kisMode string
+}
 
-	var wg sync.WaitGroup
-	var (
-		continuation []*data.Continuation
-		employment   []*data.Employment
-		jobList      *data.JobList
-		jobType      []*data.JobType
-		leo          []*data.LEO
-		salary       []*data.Salary
-		subject      *data.Subject
-	)
+// course holds the statistics documents of a course from every collection
+type course struct {
+	common       []*data.Common
+	continuation []*data.ContinuationRaw
+	degreeClass  []*data.DegreeClassRaw
+	employment   []*data.EmploymentRaw
+	entry        []*data.EntryRaw
+	jobs         []*data.JobOrder
+	jobType      []*data.JobTypeRaw
+	leo          []*data.LEORaw
+	nhsNSS       []*data.NSSRaw
+	nss          []*data.NSSRaw
+	salary       []*data.SalaryRaw
+	tariff       []*data.TariffRaw
+	subject      *data.Subject
+}
 
-	wg.Add(7)
-	go func() {
-		continuation, _ = stat.continuation()
-		wg.Done()
+// Index holds the statistics and subject of every course of a release. Each
+// collection is read once when the index is loaded, so building a course is a
+// lookup rather than a query per collection.
+type Index struct {
+	courses map[courseKey]*course
+}
 
-		return
-	}()
+// Load reads the statistics and subjects of every course of the release into an index
+func Load(st store.Store, cfg *config.Config, release string) (*Index, error) {
+	ix := &Index{courses: make(map[courseKey]*course)}
 
-	go func() {
-		employment, _ = stat.employment()
-		wg.Done()
+	find := func(db, name string, results interface{}) error {
+		if err := st.Find(cfg.Database(db), cfg.Collection(db, name), store.Key{"release": release}, results); err != nil {
+			log.ErrorC("failed to read statistics collection", err, log.Data{"database": db, "collection": name})
+			return err
+		}
 
-		return
-	}()
+		return nil
+	}
 
-	go func() {
-		jobList, _ = stat.jobList()
-		wg.Done()
+	var common []*data.Common
+	if err := find("statistics", "common", &common); err != nil {
+		return nil, err
+	}
+	for _, result := range common {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.common = append(c.common, result)
+	}
 
-		return
-	}()
+	var continuation []*data.ContinuationRaw
+	if err := find("statistics", "continuation", &continuation); err != nil {
+		return nil, err
+	}
+	for _, result := range continuation {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.continuation = append(c.continuation, result)
+	}
 
-	go func() {
-		jobType, _ = stat.jobType()
-		wg.Done()
+	var degreeClass []*data.DegreeClassRaw
+	if err := find("statistics", "degree-class", &degreeClass); err != nil {
+		return nil, err
+	}
+	for _, result := range degreeClass {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.degreeClass = append(c.degreeClass, result)
+	}
 
-		return
-	}()
+	var employment []*data.EmploymentRaw
+	if err := find("statistics", "employment", &employment); err != nil {
+		return nil, err
+	}
+	for _, result := range employment {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.employment = append(c.employment, result)
+	}
+
+	var entry []*data.EntryRaw
+	if err := find("statistics", "entry", &entry); err != nil {
+		return nil, err
+	}
+	for _, result := range entry {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.entry = append(c.entry, result)
+	}
 
-	go func() {
-		leo, _ = stat.leo()
-		wg.Done()
+	var jobs []*data.JobOrder
+	if err := find("statistics", "job-list", &jobs); err != nil {
+		return nil, err
+	}
+	for _, result := range jobs {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.jobs = append(c.jobs, result)
+	}
+
+	var jobType []*data.JobTypeRaw
+	if err := find("statistics", "job-type", &jobType); err != nil {
+		return nil, err
+	}
+	for _, result := range jobType {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.jobType = append(c.jobType, result)
+	}
+
+	var leo []*data.LEORaw
+	if err := find("statistics", "leo", &leo); err != nil {
+		return nil, err
+	}
+	for _, result := range leo {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.leo = append(c.leo, result)
+	}
+
+	var nhsNSS []*data.NSSRaw
+	if err := find("statistics", "nhs-nss", &nhsNSS); err != nil {
+		return nil, err
+	}
+	for _, result := range nhsNSS {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.nhsNSS = append(c.nhsNSS, result)
+	}
 
-		return
-	}()
+	var nss []*data.NSSRaw
+	if err := find("statistics", "nss", &nss); err != nil {
+		return nil, err
+	}
+	for _, result := range nss {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.nss = append(c.nss, result)
+	}
 
-	go func() {
-		salary, _ = stat.salary()
-		wg.Done()
+	var salary []*data.SalaryRaw
+	if err := find("statistics", "salary", &salary); err != nil {
+		return nil, err
+	}
+	for _, result := range salary {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.salary = append(c.salary, result)
+	}
 
-		return
-	}()
+	var tariff []*data.TariffRaw
+	if err := find("statistics", "tariff", &tariff); err != nil {
+		return nil, err
+	}
+	for _, result := range tariff {
+		c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode)
+		c.tariff = append(c.tariff, result)
+	}
 
-	go func() {
-		subject, _ = stat.subject()
-		wg.Done()
+	// A course's first subject is its subject
+	var subjects []*data.SubjectRaw
+	if err := find("courses", "subjects", &subjects); err != nil {
+		return nil, err
+	}
+	for _, result := range subjects {
+		if c := ix.course(result.PublicUKPRN, result.KISCourseID, result.KISMode); c.subject == nil && result.Subject != nil {
+			c.subject = result.Subject
+		}
+	}
 
-		return
-	}()
+	log.Info("read statistics of every course", log.Data{"release": release, "courses": len(ix.courses)})
 
-	wg.Wait()
+	return ix, nil
+}
 
-	stats := &data.Statistics{
-		Continuation: continuation,
-		Employment:   employment,
-		JobList:      jobList,
-		JobType:      jobType,
-		LEO:          leo,
-		Salary:       salary,
+// course returns the statistics of a course, adding an empty set if it has none yet
+func (ix *Index) course(publicUKPRN, kisCourseID, kisMode string) *course {
+	key := courseKey{publicUKPRN, kisCourseID, kisMode}
+	c, ok := ix.courses[key]
+	if !ok {
+		c = &course{}
+		ix.courses[key] = c
 	}
 
-	return stats, subject, nil
+	return c
 }
 
-func (stat *statConfig) subject() (subject *data.Subject, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
+// Get returns the statistics and subject of a course
+func (ix *Index) Get(publicUKPRN, kisCourseID, kisMode, countryCode string) (*data.Statistics, *data.Subject) {
+	c, ok := ix.courses[courseKey{publicUKPRN, kisCourseID, kisMode}]
+	if !ok {
+		c = &course{}
 	}
-	defer session.Close()
 
-	var subjectObject *data.SubjectItem
-	if err = session.DB("courses").C("subjects").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).One(&subjectObject); err != nil {
-		log.ErrorC("failed to find subject resource for course", err, nil)
+	stat := statConfig{
+		countryCode: countryCode,
+		course:      c,
 	}
 
-	subject = &data.Subject{
-		Code: subjectObject.Subject.Code,
-		Name: subjectObject.Subject.Name,
+	stats := &data.Statistics{
+		Continuation: stat.continuation(),
+		DegreeClass:  stat.degreeClass(),
+		Employment:   stat.employment(),
+		Entry:        stat.entry(),
+		JobList:      stat.jobList(),
+		JobType:      stat.jobType(),
+		LEO:          stat.leo(),
+		NHSNSS:       stat.nss(c.nhsNSS),
+		NSS:          stat.nss(c.nss),
+		Salary:       stat.salary(),
+		Tariff:       stat.tariff(),
 	}
 
-	return
+	subject := &data.Subject{}
+	if c.subject != nil {
+		subject.Code = c.subject.Code
+		subject.Name = c.subject.Name
+	} else {
+		log.Error(errors.New("no subject for course"), log.Data{"public_ukprn": publicUKPRN, "kis_course_id": kisCourseID, "kis_mode": kisMode})
+	}
+
+	return stats, subject
 }
 
-func (stat *statConfig) continuation() (continuations []*data.Continuation, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
+// common returns the first of the course's metadata (common) documents with
+// the subject, or the first of them if no subject is given
+func (stat *statConfig) common(subject string) *data.Common {
+	for _, common := range stat.course.common {
+		if subject == "" || (common.Subject != nil && common.Subject.Code == subject) {
+			return common
+		}
 	}
-	defer session.Close()
 
-	var results []*data.ContinuationRaw
-	if err = session.DB("statistics").C("continuation").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&results); err != nil {
-		log.ErrorC("failed to find continuation resources for course", err, nil)
-	}
+	return nil
+}
+
+func (stat *statConfig) continuation() (continuations []*data.Continuation) {
+	results := stat.course.continuation
 
 	for _, result := range results {
 		continuation := &data.Continuation{
@@ -176,19 +284,45 @@ func (stat *statConfig) continuation() (continuations []*data.Continuation, err
 	return
 }
 
-func (stat *statConfig) employment() (employments []*data.Employment, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
+func (stat *statConfig) degreeClass() (degreeClasses []*data.DegreeClass) {
+	results := stat.course.degreeClass
+
+	for _, result := range results {
+		degreeClass := &data.DegreeClass{
+			AggregationLevel:   result.AggregationLevel,
+			NumberOfStudents:   result.NumberOfStudents,
+			Distinction:        result.Distinction,
+			FirstClass:         result.FirstClass,
+			LowerSecondClass:   result.LowerSecondClass,
+			Merit:              result.Merit,
+			OrdinaryDegree:     result.OrdinaryDegree,
+			OtherHonoursDegree: result.OtherHonoursDegree,
+			Pass:               result.Pass,
+			UnclassifiedDegree: result.UnclassifiedDegree,
+			UpperSecondClass:   result.UpperSecondClass,
+			Subject:            result.Subject,
+		}
+
+		subjectName := ""
+		if result.Subject != nil {
+			subjectName = result.Subject.Name
+		}
+
+		if result.AggregationLevel != 0 {
+			degreeClass.Unavailable = handleDelhiUnavailableEnum(true, result.AggregationLevel, result.Unavailable, subjectName)
+		} else {
+			degreeClass.Unavailable = handleDelhiUnavailableEnum(false, result.AggregationLevel, result.Unavailable, subjectName)
+		}
 
-	var results []*data.EmploymentRaw
-	if err = session.DB("statistics").C("employment").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&results); err != nil {
-		log.ErrorC("failed to find employment resources for course", err, nil)
+		degreeClasses = append(degreeClasses, degreeClass)
 	}
 
+	return
+}
+
+func (stat *statConfig) employment() (employments []*data.Employment) {
+	results := stat.course.employment
+
 	for _, result := range results {
 		employment := &data.Employment{
 			AggregationLevel:           result.AggregationLevel,
@@ -220,21 +354,47 @@ func (stat *statConfig) employment() (employments []*data.Employment, err error)
 	return
 }
 
-func (stat *statConfig) jobList() (*data.JobList, error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return nil, err
-	}
-	defer session.Close()
+func (stat *statConfig) entry() (entries []*data.Entry) {
+	results := stat.course.entry
 
-	var jobs []data.JobOrder
+	for _, result := range results {
+		entry := &data.Entry{
+			AggregationLevel:          result.AggregationLevel,
+			NumberOfStudents:          result.NumberOfStudents,
+			AccessCourse:              result.AccessCourse,
+			ALevel:                    result.ALevel,
+			Baccalaureate:             result.Baccalaureate,
+			Degree:                    result.Degree,
+			Foundation:                result.Foundation,
+			NoQualifications:          result.NoQualifications,
+			OtherQualifications:       result.OtherQualifications,
+			OtherHigherEducationQuals: result.OtherHigherEducationQuals,
+			Subject:                   result.Subject,
+		}
 
-	if err = session.DB("statistics").C("job-list").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&jobs); err != nil {
-		log.ErrorC("failed to find job list resources for course", err, nil)
-		return nil, err
+		subjectName := ""
+		if result.Subject != nil {
+			subjectName = result.Subject.Name
+		}
+
+		if result.AggregationLevel != 0 {
+			entry.Unavailable = handleDelhiUnavailableEnum(true, result.AggregationLevel, result.Unavailable, subjectName)
+		} else {
+			entry.Unavailable = handleDelhiUnavailableEnum(false, result.AggregationLevel, result.Unavailable, subjectName)
+		}
+
+		entries = append(entries, entry)
 	}
 
+	return
+}
+
+func (stat *statConfig) jobList() *data.JobList {
+	jobs := stat.course.jobs
+
+	// Subjects are listed in the order their jobs were read, so a course's job
+	// list is the same every time it is built
+	var subjects []string
 	m := make(map[string][]data.Job)
 	for _, job := range jobs {
 		newJob := data.Job{
@@ -249,37 +409,23 @@ func (stat *statConfig) jobList() (*data.JobList, error) {
 			subjectCode = job.Subject.Code
 		}
 
+		if _, ok := m[subjectCode]; !ok {
+			subjects = append(subjects, subjectCode)
+		}
 		m[subjectCode] = append(m[subjectCode], newJob)
 	}
 
 	results := &data.JobList{}
-	for subject, jobs := range m {
+	for _, subject := range subjects {
 		item := &data.SubjectItem{
-			List: jobs,
+			List: m[subject],
 		}
 
-		var selector bson.M
-		if subject == "" {
-			selector = bson.M{
-				"public_ukprn":  stat.publicUKPRN,
-				"kis_course_id": stat.kisCourseID,
-				"kis_mode":      stat.kisMode,
-			}
-		} else {
-			selector = bson.M{
-				"public_ukprn":  stat.publicUKPRN,
-				"kis_course_id": stat.kisCourseID,
-				"kis_mode":      stat.kisMode,
-				"subject.code":  subject,
-			}
-		}
-
-		var common data.Common
-
 		// Get metadata for stats (common), e.g. aggregation level, response rate and number of students
-		if err = session.DB("statistics").C("common").Find(selector).One(&common); err != nil {
-			log.ErrorC("failed to find job list resources for course", err, nil)
-			return nil, err
+		common := stat.common(subject)
+		if common == nil {
+			log.Error(errors.New("failed to find job list metadata for course"), log.Data{"subject": subject})
+			return nil
 		}
 
 		item.AggregationLevel = common.AggregationLevel
@@ -295,36 +441,21 @@ func (stat *statConfig) jobList() (*data.JobList, error) {
 	}
 
 	if len(results.Items) < 1 {
-		var common data.Common
-
 		// Get metadata for stats (common), e.g. aggregation level, response rate and number of students
-		if err = session.DB("statistics").C("common").Find(bson.M{
-			"public_ukprn":  stat.publicUKPRN,
-			"kis_course_id": stat.kisCourseID,
-			"kis_mode":      stat.kisMode,
-		}).One(&common); err != nil {
-			log.ErrorC("failed to find job list resources for course", err, nil)
-			return nil, err
+		common := stat.common("")
+		if common == nil {
+			log.Error(errors.New("failed to find job list metadata for course"), nil)
+			return nil
 		}
 
 		results.Unavailable = handleDelhiUnavailableEnum(false, common.AggregationLevel, common.Unavailable, "")
 	}
 
-	return results, nil
+	return results
 }
 
-func (stat *statConfig) jobType() (jobTypes []*data.JobType, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	var results []*data.JobTypeRaw
-	if err = session.DB("statistics").C("job-type").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&results); err != nil {
-		log.ErrorC("failed to find job type resources for course", err, nil)
-	}
+func (stat *statConfig) jobType() (jobTypes []*data.JobType) {
+	results := stat.course.jobType
 
 	for _, result := range results {
 		jobType := &data.JobType{
@@ -354,18 +485,8 @@ func (stat *statConfig) jobType() (jobTypes []*data.JobType, err error) {
 	return
 }
 
-func (stat *statConfig) leo() (leos []*data.LEO, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	var results []*data.LEORaw
-	if err = session.DB("statistics").C("leo").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&results); err != nil {
-		log.ErrorC("failed to find leo resources for course", err, nil)
-	}
+func (stat *statConfig) leo() (leos []*data.LEO) {
+	results := stat.course.leo
 
 	for _, result := range results {
 		leo := &data.LEO{
@@ -377,7 +498,7 @@ func (stat *statConfig) leo() (leos []*data.LEO, err error) {
 			Subject:             result.Subject,
 		}
 
-		if leo.HigherQuartileRange == 0 {
+		if leo.HigherQuartileRange == nil {
 			leo.Unavailable = handleLEOUnavailableEnum(stat.countryCode, result.Unavailable)
 		}
 
@@ -387,19 +508,38 @@ func (stat *statConfig) leo() (leos []*data.LEO, err error) {
 	return
 }
 
-func (stat *statConfig) salary() (salary []*data.Salary, err error) {
-	session, err := mgo.Dial(stat.uri)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
+// nss returns the course's survey results from the nss or nhs-nss collection
+func (stat *statConfig) nss(results []*data.NSSRaw) (surveys []*data.NSS) {
+
+	for _, result := range results {
+		survey := &data.NSS{
+			AggregationLevel: result.AggregationLevel,
+			NumberOfStudents: result.NumberOfStudents,
+			ResponseRate:     result.ResponseRate,
+			Questions:        result.Questions,
+			Subject:          result.Subject,
+		}
+
+		subjectName := ""
+		if result.Subject != nil {
+			subjectName = result.Subject.Name
+		}
+
+		if result.AggregationLevel != 0 {
+			survey.Unavailable = handleDelhiUnavailableEnum(true, result.AggregationLevel, result.Unavailable, subjectName)
+		} else {
+			survey.Unavailable = handleDelhiUnavailableEnum(false, result.AggregationLevel, result.Unavailable, subjectName)
+		}
 
-	var results []*data.SalaryRaw
-	if err = session.DB("statistics").C("salary").Find(bson.M{"public_ukprn": stat.publicUKPRN, "kis_course_id": stat.kisCourseID, "kis_mode": stat.kisMode}).All(&results); err != nil {
-		log.ErrorC("failed to find salary resources for course", err, nil)
+		surveys = append(surveys, survey)
 	}
 
+	return
+}
+
+func (stat *statConfig) salary() (salary []*data.Salary) {
+	results := stat.course.salary
+
 	for _, result := range results {
 		s := &data.Salary{
 			AggregationLevel:  result.AggregationLevel,
@@ -413,7 +553,9 @@ func (stat *statConfig) salary() (salary []*data.Salary, err error) {
 			subjectName = result.Subject.Name
 		}
 
-		if result.SubjectSalarySixMonthsAfterGraduation != nil && result.SubjectSalarySixMonthsAfterGraduation.LowerQuartile != 0 {
+		// Salaries are only stored if one of the quartiles or median was published,
+		// any of which may be a genuine zero
+		if result.SubjectSalarySixMonthsAfterGraduation != nil {
 			s.LowerQuartileRange = result.SubjectSalarySixMonthsAfterGraduation.LowerQuartile
 			s.Median = result.SubjectSalarySixMonthsAfterGraduation.Median
 			s.HigherQuartileRange = result.SubjectSalarySixMonthsAfterGraduation.UpperQuartile
@@ -429,6 +571,34 @@ func (stat *statConfig) salary() (salary []*data.Salary, err error) {
 	return
 }
 
+func (stat *statConfig) tariff() (tariffs []*data.Tariff) {
+	results := stat.course.tariff
+
+	for _, result := range results {
+		tariff := &data.Tariff{
+			AggregationLevel: result.AggregationLevel,
+			NumberOfStudents: result.NumberOfStudents,
+			Tariffs:          result.Tariffs,
+			Subject:          result.Subject,
+		}
+
+		subjectName := ""
+		if result.Subject != nil {
+			subjectName = result.Subject.Name
+		}
+
+		if result.AggregationLevel != 0 {
+			tariff.Unavailable = handleDelhiUnavailableEnum(true, result.AggregationLevel, result.Unavailable, subjectName)
+		} else {
+			tariff.Unavailable = handleDelhiUnavailableEnum(false, result.AggregationLevel, result.Unavailable, subjectName)
+		}
+
+		tariffs = append(tariffs, tariff)
+	}
+
+	return
+}
+
 func handleDelhiUnavailableEnum(hasData bool, aggregationLevel int, unavailable, subjectName string) *data.Unavailable {
 
 	if aggregationLevel == 14 {
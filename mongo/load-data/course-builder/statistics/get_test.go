@@ -0,0 +1,60 @@
+package statistics
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	generalData "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// TestNullStatistic checks a statistic HESA leaves blank stays absent from
+// the course built from it while one published as zero stays zero
+func TestNullStatistic(t *testing.T) {
+	zero, dormant := 0, 5
+
+	st := store.NewMemory()
+	if err := st.Insert("statistics", "continuation", &generalData.Continuation{
+		ReleaseTag:                     generalData.ReleaseTag{Release: "2019"},
+		AggregationLevel:               14,
+		KISCourseID:                    "C1",
+		KISMode:                        "1",
+		ProportionOfStudentsContinuing: &zero,
+		ProportionOfStudentsDormant:    &dormant,
+		PublicUKPRN:                    "10000001",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := Load(st, nil, "2019")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, _ := ix.Get("10000001", "C1", "1", "XF")
+	if len(stats.Continuation) != 1 {
+		t.Fatalf("expected 1 continuation, got %d", len(stats.Continuation))
+	}
+
+	raw, err := bson.Marshal(stats.Continuation[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var continuation bson.M
+	if err = bson.Unmarshal(raw, &continuation); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := continuation["number_of_students"]; ok {
+		t.Errorf("expected number_of_students to be absent, got %v", value)
+	}
+
+	if value, ok := continuation["proportion_of_students_continuing_with_provider_after_first_year_on_course"]; !ok || value != 0 {
+		t.Errorf("expected proportion continuing to be 0, got %v", value)
+	}
+
+	if value := continuation["proportion_of_students_dormant_after_first_year_on_course"]; value != 5 {
+		t.Errorf("expected proportion dormant to be 5, got %v", value)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/ofs/alpha-scripts/mongo/load-data/validation"
+)
+
+// validate checks every row of the course file with the same mapper as a
+// load, without connecting to mongo
+func validate() []*validation.Result {
+	return []*validation.Result{
+		validation.File(src, courseFileName, 35, func(line []string) error {
+			if err := validation.Required(line, map[int]string{0: "PUBUKPRN", 1: "UKPRN", 16: "KISCOURSEID", 17: "KISMODE", 34: "KISAIMCODE"}); err != nil {
+				return err
+			}
+
+			_, err := mapCourse(line)
+			return err
+		}),
+	}
+}
@@ -2,6 +2,8 @@ package data
 
 // Location represents a course location resource
 type Location struct {
+	ReleaseTag `bson:",inline"`
+
 	ID          string `bson:"id"`
 	KISMode     string `bson:"kis_mode"`
 	KISCourseID string `bson:"kis_course_id"`
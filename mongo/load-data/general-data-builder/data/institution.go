@@ -2,6 +2,8 @@ package data
 
 // Institution contains information of the reporting institution
 type Institution struct {
+	ReleaseTag `bson:",inline"`
+
 	APROutcome             string `bson:"apr_outcome"`  // APROutcome
 	CountryCode            string `bson:"country_code"` // COUNTRY
 	PublicUKPRN            string `bson:"public_ukprn"`
@@ -2,6 +2,8 @@ package data
 
 // InstitutionLocation contains details for each teaching location
 type InstitutionLocation struct {
+	ReleaseTag `bson:",inline"`
+
 	AccommodationURL      string `bson:"accommodation_url, omitempty"`       // ACCOMURL
 	AccommodationURLWelsh string `bson:"accommodation_url_welsh, omitempty"` // ACCOMURLW
 	CountryCode           string `bson:"country_code"`                       // LOCCOUNTRY
@@ -11,6 +13,7 @@ type InstitutionLocation struct {
 	LocationNameWelsh     string `bson:"location_name_welsh, omitempty"`     // LOCNAMEW
 	LocationUKPRN         string `bson:"location_ukprn"`                     // LOCUKPRN
 	Longitude             string `bson:"longitude"`                          // LONGITUDE
+	Point                 *Point `bson:"point,omitempty"`                    // LATITUDE and LONGITUDE
 	StudentUnionURL       string `bson:"student_union_url, omitempty"`       // SUURL
 	StudentUnionURLWelsh  string `bson:"student_union_url_welsh, omitempty"` // SUURLW
 	UKPRN                 string `bson:"ukprn"`
@@ -2,11 +2,13 @@ package data
 
 // JobList contains information about common job types obtained by students
 type JobList struct {
+	ReleaseTag `bson:",inline"`
+
 	Job                  string         `bson:"job,omitempty"` // JOB
 	KISMode              string         `bson:"kis_mode"`
 	KISCourseID          string         `bson:"kis_course_id"`
 	Order                int            `bson:"order,omitempty"`                  // ORDER
-	PercentageOfStudents int            `bson:"percentage_of_students,omitempty"` // PERC
+	PercentageOfStudents *int           `bson:"percentage_of_students,omitempty"` // PERC
 	PublicUKPRN          string         `bson:"public_ukprn"`
 	SubjectObject        *SubjectObject `bson:"subject,omitempty"` // COMSBJ
 	UKPRN                string         `bson:"ukprn"`
@@ -2,13 +2,15 @@ package data
 
 // Leo represents a course longitudinal education ourcomes
 type Leo struct {
+	ReleaseTag `bson:",inline"`
+
 	AggregationLevel    int            `bson:"aggregation_level,omitempty"`     // LEOAGG
-	HigherQuartileRange int            `bson:"higher_quartile_range,omitempty"` // LEOHQ
+	HigherQuartileRange *int           `bson:"higher_quartile_range,omitempty"` // LEOUQ
 	KISMode             string         `bson:"kis_mode"`
 	KISCourseID         string         `bson:"kis_course_id"`
-	LowerQuartileRange  int            `bson:"lower_quartile_range,omitempty"` // LEOLQ
-	Median              int            `bson:"median,omitempty"`               // LEOMED
-	NumberOfGraduates   int            `bson:"number_of_graduates,omitempty"`  // LEOPOP
+	LowerQuartileRange  *int           `bson:"lower_quartile_range,omitempty"` // LEOLQ
+	Median              *int           `bson:"median,omitempty"`               // LEOMED
+	NumberOfGraduates   *int           `bson:"number_of_graduates,omitempty"`  // LEOPOP
 	PublicUKPRN         string         `bson:"public_ukprn"`
 	SubjectObject       *SubjectObject `bson:"subject,omitempty"` // LEOSBJ
 	UKPRN               string         `bson:"ukprn"`
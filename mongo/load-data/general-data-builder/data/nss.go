@@ -1,44 +1,54 @@
 package data
 
-// NSSQuestions a map of questions in the national student survey (nss)
-var NSSQuestions = map[int]string{
-	1:  "Staff are good at explaining things",
-	2:  "Staff have made the subject interesting",
-	3:  "The course is intellectually stimulating",
-	4:  "My course has challenged me to achieve my best work",
-	5:  "My course has provided me with opportunities to explore ideas or concepts in depth",
-	6:  "My course has provided me with opportunities to bring information and ideas together from different topics",
-	7:  "My course has provided me with opportunities to apply what I have learnt",
-	8:  "The criteria used in marking have been clear in advance",
-	9:  "Marking and assessment has been fair",
-	10: "Feedback on my work has been timely",
-	11: "I have received helpful comments on my work",
-	12: "I have been able to contact staff when I needed to",
-	13: "I have received sufficient advice and guidance in relation to my course",
-	14: "Good advice was available when I needed to make study choices on my course",
-	15: "The course is well organised and running smoothly",
-	16: "The timetable works efficiently for me",
-	17: "Any changes in the course or teaching have been communicated effectively",
-	18: "The IT resources and facilities provided have supported my learning well",
-	19: "The library resources (e.g. books, online services and learning spaces) have supported my learning well",
-	20: "I have been able to access course-specific resources (e.g. equipment, facilities, software, collections) when I needed to",
-	21: "I feel part of a community of staff and students",
-	22: "I have had the right opportunities to work with other students as part of my course",
-	23: "I have had the right opportunities to provide feedback on my course",
-	24: "Staff value students' views and opinions about the course",
-	25: "It is clear how students' feedback on the course has been acted on",
-	26: "The students' union (association or guild) effectively represents students' academic interests",
-	27: "Overall, I am satisfied with the quality of the course",
+// NSSQuestions lists the questions in the national student survey (nss) in question order,
+// each with the column of the NSS file holding its results
+var NSSQuestions = []Question{
+	{Column: "Q1", Number: 1, Text: "Staff are good at explaining things"},
+	{Column: "Q2", Number: 2, Text: "Staff have made the subject interesting"},
+	{Column: "Q3", Number: 3, Text: "The course is intellectually stimulating"},
+	{Column: "Q4", Number: 4, Text: "My course has challenged me to achieve my best work"},
+	{Column: "Q5", Number: 5, Text: "My course has provided me with opportunities to explore ideas or concepts in depth"},
+	{Column: "Q6", Number: 6, Text: "My course has provided me with opportunities to bring information and ideas together from different topics"},
+	{Column: "Q7", Number: 7, Text: "My course has provided me with opportunities to apply what I have learnt"},
+	{Column: "Q8", Number: 8, Text: "The criteria used in marking have been clear in advance"},
+	{Column: "Q9", Number: 9, Text: "Marking and assessment has been fair"},
+	{Column: "Q10", Number: 10, Text: "Feedback on my work has been timely"},
+	{Column: "Q11", Number: 11, Text: "I have received helpful comments on my work"},
+	{Column: "Q12", Number: 12, Text: "I have been able to contact staff when I needed to"},
+	{Column: "Q13", Number: 13, Text: "I have received sufficient advice and guidance in relation to my course"},
+	{Column: "Q14", Number: 14, Text: "Good advice was available when I needed to make study choices on my course"},
+	{Column: "Q15", Number: 15, Text: "The course is well organised and running smoothly"},
+	{Column: "Q16", Number: 16, Text: "The timetable works efficiently for me"},
+	{Column: "Q17", Number: 17, Text: "Any changes in the course or teaching have been communicated effectively"},
+	{Column: "Q18", Number: 18, Text: "The IT resources and facilities provided have supported my learning well"},
+	{Column: "Q19", Number: 19, Text: "The library resources (e.g. books, online services and learning spaces) have supported my learning well"},
+	{Column: "Q20", Number: 20, Text: "I have been able to access course-specific resources (e.g. equipment, facilities, software, collections) when I needed to"},
+	{Column: "Q21", Number: 21, Text: "I feel part of a community of staff and students"},
+	{Column: "Q22", Number: 22, Text: "I have had the right opportunities to work with other students as part of my course"},
+	{Column: "Q23", Number: 23, Text: "I have had the right opportunities to provide feedback on my course"},
+	{Column: "Q24", Number: 24, Text: "Staff value students' views and opinions about the course"},
+	{Column: "Q25", Number: 25, Text: "It is clear how students' feedback on the course has been acted on"},
+	{Column: "Q26", Number: 26, Text: "The students' union (association or guild) effectively represents students' academic interests"},
+	{Column: "Q27", Number: 27, Text: "Overall, I am satisfied with the quality of the course"},
+}
+
+// Question represents a survey question and the csv column holding its results
+type Question struct {
+	Column string
+	Number int
+	Text   string
 }
 
 // NSS contains the National Student Survey (NSS) results
 type NSS struct {
+	ReleaseTag `bson:",inline"`
+
 	AggregationLevel int            `bson:"aggregation_level,omitempty"` // NSSAGG
 	KISMode          string         `bson:"kis_mode"`
 	KISCourseID      string         `bson:"kis_course_id"`
-	NumberOfStudents int            `bson:"number_of_students,omitempty"` // NSSPOP
+	NumberOfStudents *int           `bson:"number_of_students,omitempty"` // NSSPOP
 	PublicUKPRN      string         `bson:"public_ukprn"`
-	ResponseRate     int            `bson:"response_rate"` // NSSRESP_RATE
+	ResponseRate     *int           `bson:"response_rate,omitempty"` // NSSRESP_RATE
 	Surveys          []*Survey      `bson:"survey,omitempty"`
 	SubjectObject    *SubjectObject `bson:"subject,omitempty"` // NSSSBJ
 	UKPRN            string         `bson:"ukprn"`
@@ -0,0 +1,13 @@
+package data
+
+// Point is a GeoJSON point, stored alongside the latitude and longitude a
+// location is published with so that locations can be found with $near
+type Point struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"` // longitude, latitude
+}
+
+// NewPoint returns the GeoJSON point at the latitude and longitude
+func NewPoint(latitude, longitude float64) *Point {
+	return &Point{Type: "Point", Coordinates: []float64{longitude, latitude}}
+}
@@ -0,0 +1,12 @@
+package data
+
+// ReleaseTag records the yearly HESA release a document was loaded from, so
+// that one collection can hold several releases
+type ReleaseTag struct {
+	Release string `bson:"release"`
+}
+
+// SetRelease tags the document with the release it was loaded from
+func (t *ReleaseTag) SetRelease(release string) {
+	t.Release = release
+}
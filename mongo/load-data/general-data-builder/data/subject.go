@@ -2,6 +2,8 @@ package data
 
 // Subject contains JACS level subject codes for each KISCourse
 type Subject struct {
+	ReleaseTag `bson:",inline"`
+
 	KISMode       string         `bson:"kis_mode"`
 	KISCourseID   string         `bson:"kis_course_id"`
 	PublicUKPRN   string         `bson:"public_ukprn"`
@@ -1,29 +1,39 @@
 package data
 
-// TariffDescriptions a list of tariff codes mapped to a description
-var TariffDescriptions = map[string]string{
-	"001": "less than 48 tariff points",
-	"048": "between 48 and 63 tariff points",
-	"064": "between 64 and 79 tariff points",
-	"080": "between 80 and 95 tariff points",
-	"096": "between 96 and 111 tariff points",
-	"112": "between 112 and 127 tariff points",
-	"128": "between 128 and 143 tariff points",
-	"144": "between 144 and 159 tariff points",
-	"160": "between 160 and 175 tariff points",
-	"176": "between 176 and 191 tariff points",
-	"192": "between 192 and 207 tariff points",
-	"208": "between 208 and 223 tariff points",
-	"224": "between 224 and 239 tariff points",
-	"240": "more than 240 tariff points",
+// TariffBands lists the tariff point bands in ascending order, each with the column
+// of the TARIFF file holding the proportion of entrants in that band
+var TariffBands = []TariffBand{
+	{Column: "T001", Code: "001", Description: "less than 48 tariff points"},
+	{Column: "T048", Code: "048", Description: "between 48 and 63 tariff points"},
+	{Column: "T064", Code: "064", Description: "between 64 and 79 tariff points"},
+	{Column: "T080", Code: "080", Description: "between 80 and 95 tariff points"},
+	{Column: "T096", Code: "096", Description: "between 96 and 111 tariff points"},
+	{Column: "T112", Code: "112", Description: "between 112 and 127 tariff points"},
+	{Column: "T128", Code: "128", Description: "between 128 and 143 tariff points"},
+	{Column: "T144", Code: "144", Description: "between 144 and 159 tariff points"},
+	{Column: "T160", Code: "160", Description: "between 160 and 175 tariff points"},
+	{Column: "T176", Code: "176", Description: "between 176 and 191 tariff points"},
+	{Column: "T192", Code: "192", Description: "between 192 and 207 tariff points"},
+	{Column: "T208", Code: "208", Description: "between 208 and 223 tariff points"},
+	{Column: "T224", Code: "224", Description: "between 224 and 239 tariff points"},
+	{Column: "T240", Code: "240", Description: "more than 240 tariff points"},
+}
+
+// TariffBand represents a band of tariff points and the csv column holding its results
+type TariffBand struct {
+	Column      string
+	Code        string
+	Description string
 }
 
 // Tariff contains information relating to the entry tariff points of students
 type Tariff struct {
+	ReleaseTag `bson:",inline"`
+
 	AggregationLevel int            `bson:"aggregation_level,omitempty"` // TARAGG
 	KISMode          string         `bson:"kis_mode"`
 	KISCourseID      string         `bson:"kis_course_id"`
-	NumberOfStudents int            `bson:"number_of_students,omitempty"` // TARPOP
+	NumberOfStudents *int           `bson:"number_of_students,omitempty"` // TARPOP
 	PublicUKPRN      string         `bson:"public_ukprn"`
 	Tariffs          []*TariffStats `bson:"tariff,omitempty"`  // T**
 	SubjectObject    *SubjectObject `bson:"subject,omitempty"` // TARSBJ
@@ -2,6 +2,8 @@ package data
 
 // UCASCourseID contains UCAS course identifiers for each COURSELOCATION
 type UCASCourseID struct {
+	ReleaseTag `bson:",inline"`
+
 	KISMode      string `bson:"kis_mode"`
 	KISCourseID  string `bson:"kis_course_id"`
 	LocationID   string `bson:"location_id"`
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ONSdigital/go-ns/log"
+)
+
+// columns maps a HESA column name (e.g. SALAGG) to its position within a csv row
+type columns map[string]int
+
+// get returns the value of the named column for the given csv row
+func (c columns) get(line []string, name string) string {
+	i, ok := c[name]
+	if !ok {
+		panic("column " + name + " has not been declared for this file")
+	}
+
+	return line[i]
+}
+
+// HeaderError contains the differences between the header row of a csv file and
+// the columns the handler expects to find in it
+type HeaderError struct {
+	FileName string
+	Missing  []string
+	Extra    []string
+	Renamed  map[string]string // expected column name -> column name found in file
+}
+
+func (e *HeaderError) Error() string {
+	var problems []string
+
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing columns: "+strings.Join(e.Missing, ", "))
+	}
+
+	if len(e.Renamed) > 0 {
+		var renamed []string
+		for _, expected := range sortedKeys(e.Renamed) {
+			renamed = append(renamed, expected+" -> "+e.Renamed[expected])
+		}
+		problems = append(problems, "renamed columns: "+strings.Join(renamed, ", "))
+	}
+
+	if len(e.Extra) > 0 {
+		problems = append(problems, "extra columns: "+strings.Join(e.Extra, ", "))
+	}
+
+	return fmt.Sprintf("unexpected header row in %s; %s", e.FileName, strings.Join(problems, "; "))
+}
+
+// LogData returns the header report in a form suitable for logging
+func (e *HeaderError) LogData() log.Data {
+	return log.Data{
+		"file_name":       e.FileName,
+		"missing_columns": e.Missing,
+		"extra_columns":   e.Extra,
+		"renamed_columns": e.Renamed,
+	}
+}
+
+// mapColumns checks the header row of a csv file against the expected columns and
+// returns the position of each expected column. Columns are matched by name so a
+// reordered file still loads correctly; any expected column that cannot be found
+// results in a HeaderError. A column sitting in the position of a missing column
+// under an unknown name is reported as renamed. Extra columns do not stop a load
+// when every expected column is present; their names are returned so they can
+// be reported with the dataset's result.
+func mapColumns(fileName string, header, expected []string) (columns, []string, error) {
+	positions := make(map[string]int)
+	for i, name := range header {
+		positions[columnName(name)] = i
+	}
+
+	isExpected := make(map[string]bool)
+	for _, name := range expected {
+		isExpected[name] = true
+	}
+
+	headerErr := &HeaderError{
+		FileName: fileName,
+		Renamed:  make(map[string]string),
+	}
+
+	cols := make(columns)
+	for i, name := range expected {
+		if position, ok := positions[name]; ok {
+			cols[name] = position
+			continue
+		}
+
+		if i < len(header) {
+			found := columnName(header[i])
+			if !isExpected[found] {
+				headerErr.Renamed[name] = found
+				continue
+			}
+		}
+
+		headerErr.Missing = append(headerErr.Missing, name)
+	}
+
+	renamedTo := make(map[string]bool)
+	for _, found := range headerErr.Renamed {
+		renamedTo[found] = true
+	}
+
+	for _, name := range header {
+		name = columnName(name)
+		if !isExpected[name] && !renamedTo[name] {
+			headerErr.Extra = append(headerErr.Extra, name)
+		}
+	}
+
+	if len(headerErr.Missing) > 0 || len(headerErr.Renamed) > 0 {
+		return nil, nil, headerErr
+	}
+
+	if len(headerErr.Extra) > 0 {
+		log.Info("ignoring extra columns in file", headerErr.LogData())
+	}
+
+	return cols, headerErr.Extra, nil
+}
+
+// fixedColumns returns the position of each column for files which are delivered
+// without a header row
+func fixedColumns(expected []string) columns {
+	cols := make(columns)
+	for i, name := range expected {
+		cols[name] = i
+	}
+
+	return cols
+}
+
+// readHeader reads the header row of a csv file and maps it to the expected
+// columns, returning the number of fields in the header row and the names of
+// any extra columns
+func readHeader(csvReader *csv.Reader, fileName string, expected []string) (cols columns, fields int, extra []string, err error) {
+	header, err := csvReader.Read()
+	if err != nil {
+		log.ErrorC("encountered error immediately when processing header row", err, nil)
+		return nil, 0, nil, err
+	}
+
+	cols, extra, err = mapColumns(fileName, header, expected)
+	if err != nil {
+		if headerErr, ok := err.(*HeaderError); ok {
+			log.ErrorC("header row does not match expected columns", err, headerErr.LogData())
+		}
+		return nil, 0, nil, err
+	}
+
+	return cols, len(header), extra, nil
+}
+
+// columnName strips whitespace and any byte order mark from a header cell
+func columnName(cell string) string {
+	return strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapColumnsReturnsExtraColumns(t *testing.T) {
+	cols, extra, err := mapColumns("SALARY.csv", []string{"\ufeffPUBUKPRN", "NOTES", "UKPRN", " SOURCE "}, []string{"PUBUKPRN", "UKPRN"})
+	if err != nil {
+		t.Fatalf("expected extra columns not to stop the load, got %v", err)
+	}
+
+	if !reflect.DeepEqual(cols, columns{"PUBUKPRN": 0, "UKPRN": 2}) {
+		t.Errorf("got columns %v", cols)
+	}
+
+	if !reflect.DeepEqual(extra, []string{"NOTES", "SOURCE"}) {
+		t.Errorf("got extra columns %q, want NOTES and SOURCE", extra)
+	}
+}
@@ -1,11 +1,25 @@
 package handlers
 
-import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/mongo"
+import (
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
 
 var fileExtension = ".csv"
 
+// DefaultBatchSize is the number of documents written to mongo in each bulk write
+const DefaultBatchSize = 1000
+
 // Common ...
 type Common struct {
-	Mongo                *mongo.Mongo
-	RelativeFileLocation string
+	Store            store.Store    // documents are loaded into, mongo unless writing to files
+	Source           *source.Source // directory or zip archive the csv files are read from
+	Release          string         // HESA release documents are tagged with, e.g. 2018
+	XMLFile          string         // KIS XML file in the source to read datasets from instead of their csv files
+	BatchSize        int
+	RejectsLocation  string   // directory the reject file of each dataset is written to
+	MaxRejects       int      // rows a dataset may reject before its load is abandoned, negative for no limit
+	MaxRejectPercent float64  // percentage of rows a dataset may reject, negative for no limit
+	ChangesLocation  string   // directory the change report of each dataset is written to by a diff
+	Subjects         Subjects // CAH code dictionary, set once the CAH codes are loaded
 }
@@ -1,104 +1,51 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateCommonData ...
-func (c *Common) CreateCommonData(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// commonDataDataset loads the COMMON file into statistics.common
+var commonDataDataset = &Dataset{
+	Name:       "COMMON",
+	FileName:   "COMMON",
+	Database:   "statistics",
+	Collection: "common",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "COMUNAVAILREASON", "COMPOP",
+		"COMRESP_RATE", "COMAGG", "COMSBJ",
+	},
+	SubjectColumn: "COMSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/COMMON",
+	Map:           mapCommonData,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapCommonData maps a row of the COMMON file to a common data resource
+func mapCommonData(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	commonData := &data.CommonData{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		Unavailable:   cols.get(line, "COMUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "COMAGG") != "" {
+		commonData.AggregationLevel, err = cols.atoi(line, "COMAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		commonData := &data.CommonData{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			UKPRN:       line[1],
-			PublicUKPRN: line[0],
-			Unavailable: line[4],
-		}
-
-		if line[7] != "" {
-			commonData.AggregationLevel, err = strconv.Atoi(line[7])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			commonData.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[6] != "" {
-			commonData.ResponseRate, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[8] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[8])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "common_data_resource": commonData})
-			}
-
-			if subjectObject != nil {
-				commonData.SubjectObject = subjectObject
-			}
-		}
-
-		if err := m.AddCommonData(database, collection, commonData); err != nil {
-			log.ErrorC("failed to add common data resource", err, log.Data{"line_count": count, "common_data_resource": commonData})
-			return err
+			return nil, err
 		}
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if commonData.NumberOfStudents, err = cols.number(line, "COMPOP"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created common data resources", nil)
+	if commonData.ResponseRate, err = cols.number(line, "COMRESP_RATE"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return commonData, nil
 }
@@ -1,132 +1,67 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateContinuation ...
-func (c *Common) CreateContinuation(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// continuationDataset loads the CONTINUATION file into statistics.continuation
+var continuationDataset = &Dataset{
+	Name:       "CONTINUATION",
+	FileName:   "CONTINUATION",
+	Database:   "statistics",
+	Collection: "continuation",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "CONTUNAVAILREASON", "CONTPOP",
+		"CONTAGG", "CONTSBJ", "UCONT", "UDORMANT", "UGAINED", "ULEFT", "ULOWER",
+	},
+	SubjectColumn: "CONTSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/CONTINUATION",
+	Map:           mapContinuation,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapContinuation maps a row of the CONTINUATION file to a continuation resource
+func mapContinuation(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	continuation := &data.Continuation{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "CONTUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "CONTAGG") != "" {
+		continuation.AggregationLevel, err = cols.atoi(line, "CONTAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		continuation := &data.Continuation{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[6] != "" {
-			continuation.AggregationLevel, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			continuation.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[8] != "" {
-			continuation.ProportionOfStudentsContinuing, err = strconv.Atoi(line[8])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[9] != "" {
-			continuation.ProportionOfStudentsDormant, err = strconv.Atoi(line[9])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[10] != "" {
-			continuation.ProportionOfStudentsGainExpectedOrHigherAward, err = strconv.Atoi(line[10])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[12] != "" {
-			continuation.ProportionOfStudentsGainLowerAward, err = strconv.Atoi(line[12])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[11] != "" {
-			continuation.ProportionOfStudentsLeft, err = strconv.Atoi(line[11])
-			if err != nil {
-				return err
-			}
-		}
+	if continuation.NumberOfStudents, err = cols.number(line, "CONTPOP"); err != nil {
+		return nil, err
+	}
 
-		if line[7] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[7])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "continuation_resource": continuation})
-			}
+	if continuation.ProportionOfStudentsContinuing, err = cols.number(line, "UCONT"); err != nil {
+		return nil, err
+	}
 
-			if subjectObject != nil {
-				continuation.SubjectObject = subjectObject
-			}
-		}
+	if continuation.ProportionOfStudentsDormant, err = cols.number(line, "UDORMANT"); err != nil {
+		return nil, err
+	}
 
-		if err := m.AddContinuation(database, collection, continuation); err != nil {
-			log.ErrorC("failed to add continuation resource", err, log.Data{"line_count": count, "continuation_resource": continuation})
-			return err
-		}
+	if continuation.ProportionOfStudentsGainExpectedOrHigherAward, err = cols.number(line, "UGAINED"); err != nil {
+		return nil, err
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if continuation.ProportionOfStudentsGainLowerAward, err = cols.number(line, "ULOWER"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created continuation resources", nil)
+	if continuation.ProportionOfStudentsLeft, err = cols.number(line, "ULEFT"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return continuation, nil
 }
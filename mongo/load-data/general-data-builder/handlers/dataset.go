@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
+	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// Dataset describes a HESA csv file and how each of its rows is mapped to a
+// document in mongo
+type Dataset struct {
+	Name          string // identifies the dataset in logs
+	FileName      string // name of the csv file without its extension
+	Database      string
+	Collection    string
+	Columns       []string            // columns expected in the header row
+	Headerless    bool                // file is delivered without a header row, columns are read by position
+	SubjectColumn string              // column holding the CAH code of the row's subject, if any
+	Required      []string            // columns which must not be blank
+	Codes         map[string][]string // coded columns and the codes each may hold
+	XMLPath       string              // element holding each row in the KIS XML file below its root, if the dataset is published there
+	Reference     bool                // holds reference data shared by every release, so its documents are not tagged with one
+	Key           []string            // paths to the fields of a document which identify it, used to match rows with stored documents in a diff
+	Indexes       []store.Index       // indexes built on the collection before it is loaded
+	Map           func(cols columns, line []string, subject *data.SubjectObject) (interface{}, error)
+}
+
+// Datasets are loaded once the CAH codes are in place, the first has the
+// largest number of rows so is started first
+var Datasets = []*Dataset{
+	jobListDataset,
+	subjectDataset,
+	courseLocationDataset,
+	qualificationDataset,
+	ucasCourseIDDataset,
+	institutionDataset,
+	institutionLocationDataset,
+	commonDataDataset,
+	continuationDataset,
+	degreeClassDataset,
+	employmentDataset,
+	entryDataset,
+	jobTypeDataset,
+	leoDataset,
+	nhsNSSDataset,
+	nssDataset,
+	salaryDataset,
+	tariffDataset,
+}
+
+// Configure names the file, database and collection of every dataset from the
+// config, it must be called once before any dataset is selected
+func Configure(cfg *config.Config) {
+	for _, dataset := range append([]*Dataset{CAHCodes}, Datasets...) {
+		dataset.FileName = cfg.File(dataset.Name, dataset.FileName)
+		dataset.Collection = cfg.Collection(dataset.Database, dataset.Collection)
+		dataset.Database = cfg.Database(dataset.Database)
+	}
+}
+
+// Select returns the datasets named, or every dataset if no names are given,
+// less any which are skipped. CAH codes are selected first whenever a chosen
+// dataset looks up subjects, unless they are skipped.
+func Select(names, skip []string) ([]*Dataset, error) {
+	all := append([]*Dataset{CAHCodes}, Datasets...)
+
+	chosen := make(map[*Dataset]bool)
+	if len(names) == 0 {
+		for _, dataset := range all {
+			chosen[dataset] = true
+		}
+	}
+
+	for _, name := range names {
+		dataset := find(all, name)
+		if dataset == nil {
+			return nil, fmt.Errorf("unknown dataset %s", name)
+		}
+		chosen[dataset] = true
+	}
+
+	for _, name := range skip {
+		dataset := find(all, name)
+		if dataset == nil {
+			return nil, fmt.Errorf("unknown dataset %s", name)
+		}
+		chosen[dataset] = false
+	}
+
+	var selected []*Dataset
+	for _, dataset := range Datasets {
+		if chosen[dataset] {
+			selected = append(selected, dataset)
+		}
+	}
+
+	if _, named := chosen[CAHCodes]; !named && NeedSubjects(selected) {
+		chosen[CAHCodes] = true
+	}
+
+	if chosen[CAHCodes] {
+		selected = append([]*Dataset{CAHCodes}, selected...)
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no datasets selected")
+	}
+
+	return selected, nil
+}
+
+// NeedSubjects reports whether any of the datasets look up subjects
+func NeedSubjects(datasets []*Dataset) bool {
+	for _, dataset := range datasets {
+		if dataset.SubjectColumn != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func find(datasets []*Dataset, name string) *Dataset {
+	for _, dataset := range datasets {
+		if strings.EqualFold(dataset.Name, strings.TrimSpace(name)) {
+			return dataset
+		}
+	}
+
+	return nil
+}
+
+// releaseTagged is a document which records the release it was loaded from
+type releaseTagged interface {
+	SetRelease(release string)
+}
+
+// Progress reports the number of documents added to a dataset since its last report
+type Progress struct {
+	Dataset string
+	Count   int
+}
+
+// Load adds a document for each row of the dataset's csv file to a staging
+// collection, which replaces the live collection once every row has been
+// written. Rows which cannot be loaded are written to the dataset's reject
+// file and the load is abandoned, leaving the live collection untouched, if
+// more are rejected than allowed.
+func (c *Common) Load(dataset *Dataset, counter chan<- Progress) *Result {
+	result := &Result{Dataset: dataset.Name}
+
+	start := time.Now()
+	err := c.load(dataset, counter, result)
+	result.finish(start, err)
+
+	return result
+}
+
+func (c *Common) load(dataset *Dataset, counter chan<- Progress, result *Result) error {
+	s := c.Store
+	staging := store.Staging(dataset.Collection)
+	logData := log.Data{"dataset": dataset.Name, "file name": dataset.FileName, "collection": staging}
+
+	if err := c.prepareStaging(dataset, logData); err != nil {
+		return err
+	}
+
+	// Keep every other release, the staging collection replaces them all
+	if !dataset.Reference {
+		if err := s.CopyOtherReleases(dataset.Database, dataset.Collection, c.Release); err != nil {
+			return err
+		}
+	}
+
+	if err := c.ensureIndexes(dataset, staging, result); err != nil {
+		return err
+	}
+
+	rejects, err := c.openRejects(dataset)
+	if err != nil {
+		log.ErrorC("failed to remove previous reject file", err, logData)
+		return err
+	}
+	defer func() {
+		if err := rejects.close(); err != nil {
+			log.ErrorC("failed to write reject file", err, log.Data{"dataset": dataset.Name, "file name": rejects.path})
+		}
+	}()
+
+	b := &batch{}
+	err = c.readRows(dataset, result, func(lineNumber int, cols columns, line []string) error {
+		document, err := c.mapRow(dataset, lineNumber, cols, line, rejects, result)
+		if err != nil || document == nil {
+			return err
+		}
+
+		b.add(document, lineNumber, line)
+		if len(b.documents) >= c.batchSize() {
+			if err := c.insertBatch(dataset, staging, b, counter, rejects, result); err != nil {
+				return err
+			}
+			b = &batch{}
+		}
+
+		return nil
+	}, c.rejectRead(rejects, result))
+	if err != nil {
+		return err
+	}
+
+	if err := c.insertBatch(dataset, staging, b, counter, rejects, result); err != nil {
+		return err
+	}
+
+	if len(result.UnknownCAHCodes) > 0 {
+		log.Info("rows with unknown cah codes have been loaded without a subject", log.Data{"dataset": dataset.Name, "unknown_cah_codes": result.UnknownCAHCodes})
+	}
+
+	if err := c.checkRejectPercent(rejects, result); err != nil {
+		log.ErrorC("too many rows rejected, live collection has not been replaced", err, logData)
+		return err
+	}
+
+	var count int
+	if dataset.Reference {
+		count, err = s.Count(dataset.Database, staging, nil)
+	} else {
+		count, err = s.Count(dataset.Database, staging, store.Key{"release": c.Release})
+	}
+	if err != nil {
+		log.ErrorC("failed to count documents in staging collection", err, logData)
+		return err
+	}
+
+	if count == 0 || count != result.RowsWritten {
+		err = fmt.Errorf("staging collection %s.%s holds %d documents of the release but %d were written", dataset.Database, staging, count, result.RowsWritten)
+		log.ErrorC("staging collection failed validation, live collection has not been replaced", err, logData)
+		return err
+	}
+
+	if err = s.Swap(dataset.Database, dataset.Collection); err != nil {
+		log.ErrorC("failed to swap staging collection into place", err, logData)
+		return err
+	}
+
+	log.Info("created resources", log.Data{"dataset": dataset.Name, "release": c.Release, "documents": count, "rejected": result.RowsRejected})
+
+	return nil
+}
+
+// prepareStaging empties the dataset's staging collection, left over from a
+// previous load, and checks the dataset can be loaded
+func (c *Common) prepareStaging(dataset *Dataset, logData log.Data) error {
+	if err := c.Store.Drop(dataset.Database, store.Staging(dataset.Collection)); err != nil {
+		log.ErrorC("failed to drop staging collection", err, logData)
+		return err
+	}
+
+	if !dataset.Reference && c.Release == "" {
+		err := errors.New("a release must be given to load a dataset which is not reference data")
+		log.ErrorC("missing release", err, logData)
+		return err
+	}
+
+	if dataset.SubjectColumn != "" && c.Subjects == nil {
+		err := errors.New("cah codes must be loaded before a dataset with a subject column")
+		log.ErrorC("missing cah code dictionary", err, logData)
+		return err
+	}
+
+	return nil
+}
+
+// mapRow checks a row and maps it to a document tagged with the release. A
+// row which cannot be mapped is rejected and no document is returned; an error
+// is only returned once the dataset has rejected more rows than allowed.
+func (c *Common) mapRow(dataset *Dataset, lineNumber int, cols columns, line []string, rejects *reportFile, result *Result) (interface{}, error) {
+	result.RowsRead++
+
+	err := dataset.check(cols, line)
+	var document interface{}
+	if err == nil {
+		document, err = dataset.Map(cols, line, c.subject(dataset, cols, line, result))
+	}
+
+	if err != nil {
+		rowErr := newRowError(err, lineNumber, line)
+		log.ErrorC("rejected row", rowErr, log.Data{"dataset": dataset.Name, "line_number": lineNumber})
+		return nil, c.reject(rejects, rowErr, result)
+	}
+
+	if tagged, ok := document.(releaseTagged); ok {
+		tagged.SetRelease(c.Release)
+	}
+
+	return document, nil
+}
+
+// readRows reads the dataset's csv file, checking its header row and noting
+// any extra columns in the result if one is given, or its elements of the KIS
+// XML file if one is used, and calls fn with every row and its line number in
+// the file. A row which cannot be parsed, or has a
+// different number of fields to the header row, is passed to rejectRow instead
+// and the rest of the file is still read; only an error reading the file stops
+// it.
+func (c *Common) readRows(dataset *Dataset, result *Result, fn func(lineNumber int, cols columns, line []string) error, rejectRow func(rowErr *RowError) error) error {
+	if c.XMLFile != "" && dataset.XMLPath != "" {
+		return c.readXMLRows(dataset, fn)
+	}
+
+	csvFile, err := c.Source.Open(dataset.FileName + fileExtension)
+	if err != nil {
+		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"dataset": dataset.Name, "file name": dataset.FileName})
+		return err
+	}
+	defer csvFile.Close()
+
+	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+
+	// Header row is line 1 of a file which has one
+	lineNumber := 0
+	cols, fields := fixedColumns(dataset.Columns), len(dataset.Columns)
+	if !dataset.Headerless {
+		var extra []string
+		if cols, fields, extra, err = readHeader(csvReader, dataset.FileName, dataset.Columns); err != nil {
+			return err
+		}
+		lineNumber = 1
+
+		if result != nil {
+			result.ExtraColumns = extra
+		}
+	}
+
+	// Rows are checked against the header row here so that a short or long
+	// row is rejected rather than stopping the read
+	csvReader.FieldsPerRecord = -1
+
+	for {
+		line, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		lineNumber++
+
+		var rowErr *RowError
+		switch parseErr, ok := err.(*csv.ParseError); {
+		case ok:
+			rowErr = &RowError{LineNumber: lineNumber, Reason: parseErr.Err.Error(), Line: line}
+		case err != nil:
+			log.ErrorC("encountered error reading csv", err, log.Data{"dataset": dataset.Name, "line_number": lineNumber})
+			return err
+		case len(line) != fields:
+			rowErr = &RowError{LineNumber: lineNumber, Reason: fmt.Sprintf("expected %d fields, found %d", fields, len(line)), Line: line}
+		}
+
+		if rowErr != nil {
+			log.ErrorC("rejected row", rowErr, log.Data{"dataset": dataset.Name, "line_number": lineNumber})
+			err = rejectRow(rowErr)
+		} else {
+			err = fn(lineNumber, cols, line)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// subject returns the subject of a row from the CAH code dictionary, counting
+// any code which is not in the dictionary
+func (c *Common) subject(dataset *Dataset, cols columns, line []string, result *Result) *data.SubjectObject {
+	if dataset.SubjectColumn == "" || c.Subjects == nil {
+		return nil
+	}
+
+	code := cols.get(line, dataset.SubjectColumn)
+	if code == "" {
+		return nil
+	}
+
+	subject, ok := c.Subjects[code]
+	if !ok {
+		result.addUnknownCAHCode(code)
+	}
+
+	return subject
+}
+
+// batch holds documents waiting to be written along with the csv line each
+// document was mapped from
+type batch struct {
+	documents   []interface{}
+	lineNumbers []int
+	lines       [][]string
+}
+
+func (b *batch) add(document interface{}, lineNumber int, line []string) {
+	b.documents = append(b.documents, document)
+	b.lineNumbers = append(b.lineNumbers, lineNumber)
+	b.lines = append(b.lines, line)
+}
+
+// insertBatch writes a batch of documents in a single bulk write, rejecting
+// the csv line of each document which could not be added
+func (c *Common) insertBatch(dataset *Dataset, collection string, b *batch, counter chan<- Progress, rejects *reportFile, result *Result) error {
+	if len(b.documents) == 0 {
+		return nil
+	}
+
+	failed, err := c.Store.BulkInsert(dataset.Database, collection, b.documents)
+	if err != nil {
+		log.ErrorC("failed to add batch of resources", err, log.Data{
+			"dataset":           dataset.Name,
+			"first_line_number": b.lineNumbers[0],
+			"last_line_number":  b.lineNumbers[len(b.lineNumbers)-1],
+		})
+		return err
+	}
+
+	written := len(b.documents) - len(failed)
+	result.RowsWritten += written
+	counter <- Progress{Dataset: dataset.Name, Count: written}
+
+	for _, insertErr := range failed {
+		rowErr := &RowError{Reason: insertErr.Err.Error()}
+		logData := log.Data{"dataset": dataset.Name}
+		if insertErr.Index >= 0 && insertErr.Index < len(b.documents) {
+			rowErr.LineNumber = b.lineNumbers[insertErr.Index]
+			rowErr.Line = b.lines[insertErr.Index]
+			logData["line_number"] = rowErr.LineNumber
+			logData["resource"] = b.documents[insertErr.Index]
+		}
+
+		log.ErrorC("failed to add resource", insertErr.Err, logData)
+
+		if err := c.reject(rejects, rowErr, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchSize returns the number of documents written in each bulk write
+func (c *Common) batchSize() int {
+	if c.BatchSize < 1 {
+		return DefaultBatchSize
+	}
+
+	return c.BatchSize
+}
+
+// CheckHeader opens the dataset's csv file and checks its header row against
+// the expected columns without loading any data
+func (c *Common) CheckHeader(dataset *Dataset) error {
+	if dataset.Headerless || (c.XMLFile != "" && dataset.XMLPath != "") {
+		return nil
+	}
+
+	csvFile, err := c.Source.Open(dataset.FileName + fileExtension)
+	if err != nil {
+		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": dataset.FileName})
+		return err
+	}
+	defer csvFile.Close()
+
+	header, err := csv.NewReader(csvFile).Read()
+	if err != nil {
+		log.ErrorC("encountered error immediately when processing header row", err, log.Data{"file name": dataset.FileName})
+		return err
+	}
+
+	_, _, err = mapColumns(dataset.FileName, header, dataset.Columns)
+	return err
+}
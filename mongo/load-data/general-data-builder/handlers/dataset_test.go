@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func datasetNames(datasets []*Dataset) (names []string) {
+	for _, dataset := range datasets {
+		names = append(names, dataset.Name)
+	}
+
+	return
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		names, skip []string
+		want        []string
+	}{
+		{names: []string{"nss", "TARIFF"}, want: []string{"CAHCODES", "NSS", "TARIFF"}},
+		{names: []string{"KISAIMS"}, want: []string{"KISAIMS"}},
+		{names: []string{"NSS"}, skip: []string{"CAHCODES"}, want: []string{"NSS"}},
+		{names: []string{"CAHCODES"}, want: []string{"CAHCODES"}},
+	}
+
+	for _, test := range tests {
+		selected, err := Select(test.names, test.skip)
+		if err != nil {
+			t.Errorf("Select(%v, %v) returned error: %v", test.names, test.skip, err)
+			continue
+		}
+
+		if got := datasetNames(selected); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Select(%v, %v) = %v, want %v", test.names, test.skip, got, test.want)
+		}
+	}
+
+	all, err := Select(nil, []string{"JOBLIST"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != len(Datasets) || all[0] != CAHCodes {
+		t.Errorf("expected CAH codes followed by every dataset but JOBLIST, got %v", datasetNames(all))
+	}
+
+	for _, dataset := range all {
+		if dataset == jobListDataset {
+			t.Error("expected JOBLIST to be skipped")
+		}
+	}
+
+	if _, err = Select([]string{"NOPE"}, nil); err == nil {
+		t.Error("expected an error for an unknown dataset")
+	}
+}
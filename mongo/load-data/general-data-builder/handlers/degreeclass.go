@@ -1,160 +1,84 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateDegreeClass ...
-func (c *Common) CreateDegreeClass(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// degreeClassDataset loads the DEGREECLASS file into statistics.degree-class
+var degreeClassDataset = &Dataset{
+	Name:       "DEGREECLASS",
+	FileName:   "DEGREECLASS",
+	Database:   "statistics",
+	Collection: "degree-class",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "DEGUNAVAILREASON", "DEGPOP",
+		"DEGAGG", "DEGSBJ", "UFIRST", "UUPPER", "ULOWER", "UOTHER", "UORDINARY",
+		"UDISTINCTION", "UMERIT", "UPASS", "UNA",
+	},
+	SubjectColumn: "DEGSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/DEGREECLASS",
+	Map:           mapDegreeClass,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapDegreeClass maps a row of the DEGREECLASS file to a degree class resource
+func mapDegreeClass(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	degreeClass := &data.DegreeClass{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "DEGUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "DEGAGG") != "" {
+		degreeClass.AggregationLevel, err = cols.atoi(line, "DEGAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		degreeClass := &data.DegreeClass{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[6] != "" {
-			degreeClass.AggregationLevel, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			degreeClass.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[13] != "" {
-			degreeClass.ProportionOfStudentsGainDistinction, err = strconv.Atoi(line[13])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[8] != "" {
-			degreeClass.ProportionOfStudentsGainFirstClass, err = strconv.Atoi(line[8])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[10] != "" {
-			degreeClass.ProportionOfStudentsGainLowerSecondClass, err = strconv.Atoi(line[10])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[14] != "" {
-			degreeClass.ProportionOfStudentsGainMerit, err = strconv.Atoi(line[14])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[15] != "" {
-			degreeClass.ProportionOfStudentsGainPass, err = strconv.Atoi(line[15])
-			if err != nil {
-				return err
-			}
-		}
+	if degreeClass.NumberOfStudents, err = cols.number(line, "DEGPOP"); err != nil {
+		return nil, err
+	}
 
-		if line[12] != "" {
-			degreeClass.ProportionOfStudentsGainOrdinaryDegree, err = strconv.Atoi(line[12])
-			if err != nil {
-				return err
-			}
-		}
+	if degreeClass.ProportionOfStudentsGainDistinction, err = cols.number(line, "UDISTINCTION"); err != nil {
+		return nil, err
+	}
 
-		if line[11] != "" {
-			degreeClass.ProportionOfStudentsGainOtherHonoursDegree, err = strconv.Atoi(line[11])
-			if err != nil {
-				return err
-			}
-		}
+	if degreeClass.ProportionOfStudentsGainFirstClass, err = cols.number(line, "UFIRST"); err != nil {
+		return nil, err
+	}
 
-		if line[16] != "" {
-			degreeClass.ProportionOfStudentsGainUnclassifiedDegree, err = strconv.Atoi(line[16])
-			if err != nil {
-				return err
-			}
-		}
+	if degreeClass.ProportionOfStudentsGainLowerSecondClass, err = cols.number(line, "ULOWER"); err != nil {
+		return nil, err
+	}
 
-		if line[9] != "" {
-			degreeClass.ProportionOfStudentsGainUpperSecondClass, err = strconv.Atoi(line[9])
-			if err != nil {
-				return err
-			}
-		}
+	if degreeClass.ProportionOfStudentsGainMerit, err = cols.number(line, "UMERIT"); err != nil {
+		return nil, err
+	}
 
-		if line[7] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[7])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "degree_class_resource": degreeClass})
-			}
+	if degreeClass.ProportionOfStudentsGainPass, err = cols.number(line, "UPASS"); err != nil {
+		return nil, err
+	}
 
-			if subjectObject != nil {
-				degreeClass.SubjectObject = subjectObject
-			}
-		}
+	if degreeClass.ProportionOfStudentsGainOrdinaryDegree, err = cols.number(line, "UORDINARY"); err != nil {
+		return nil, err
+	}
 
-		if err := m.AddDegreeClass(database, collection, degreeClass); err != nil {
-			log.ErrorC("failed to add degree class resource", err, log.Data{"line_count": count, "degree_class_resource": degreeClass})
-			return err
-		}
+	if degreeClass.ProportionOfStudentsGainOtherHonoursDegree, err = cols.number(line, "UOTHER"); err != nil {
+		return nil, err
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if degreeClass.ProportionOfStudentsGainUnclassifiedDegree, err = cols.number(line, "UNA"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created degree class resources", nil)
+	if degreeClass.ProportionOfStudentsGainUpperSecondClass, err = cols.number(line, "UUPPER"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return degreeClass, nil
 }
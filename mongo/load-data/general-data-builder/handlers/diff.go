@@ -0,0 +1,489 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// statisticsKey is the natural key shared by the statistics datasets, as paths
+// to fields of their documents: a course's statistics for one of its subjects.
+// Datasets with a longer key list it in full rather than appending to this one,
+// which would share its backing array.
+var statisticsKey = []string{"public_ukprn", "ukprn", "kis_course_id", "kis_mode", "subject.code"}
+
+// Kinds of change written to the change report
+const (
+	changeInsert = "insert"
+	changeUpdate = "update"
+	changeDelete = "delete"
+)
+
+// Changes counts the documents an incremental load changed
+type Changes struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+	Kept      int `json:"kept,omitempty"` // documents missing from the file which were not deleted as rows were rejected
+}
+
+// storedDocument is a document of the release already stored
+type storedDocument struct {
+	key  store.Key // matches the document alone, to change it
+	hash [sha1.Size]byte
+	seen bool
+}
+
+// Diff compares every row of the dataset's csv file with the documents of the
+// release already stored, matching them by the dataset's key, and writes
+// only the documents which were added, changed or removed. Changes are made
+// to a copy of the live collection which replaces it once they have all been
+// written, and each change is listed in the dataset's change report. Stored
+// documents missing from the file are kept if any row was rejected, as they
+// may belong to a rejected row.
+func (c *Common) Diff(dataset *Dataset, counter chan<- Progress) *Result {
+	result := &Result{Dataset: dataset.Name, Changes: &Changes{}}
+
+	start := time.Now()
+	err := c.diff(dataset, counter, result)
+	result.finish(start, err)
+
+	return result
+}
+
+func (c *Common) diff(dataset *Dataset, counter chan<- Progress, result *Result) error {
+	s := c.Store
+	staging := store.Staging(dataset.Collection)
+	logData := log.Data{"dataset": dataset.Name, "file name": dataset.FileName, "collection": staging}
+
+	if len(dataset.Key) == 0 {
+		err := errors.New("dataset has no key to match rows with stored documents")
+		log.ErrorC("unable to diff dataset", err, logData)
+		return err
+	}
+
+	if err := c.prepareStaging(dataset, logData); err != nil {
+		return err
+	}
+
+	if err := s.CopyToStaging(dataset.Database, dataset.Collection); err != nil {
+		return err
+	}
+
+	if err := c.ensureIndexes(dataset, staging, result); err != nil {
+		return err
+	}
+
+	stored, err := c.storedDocuments(dataset, staging)
+	if err != nil {
+		log.ErrorC("failed to read stored documents", err, logData)
+		return err
+	}
+
+	rejects, err := c.openRejects(dataset)
+	if err != nil {
+		log.ErrorC("failed to remove previous reject file", err, logData)
+		return err
+	}
+	defer func() {
+		if err := rejects.close(); err != nil {
+			log.ErrorC("failed to write reject file", err, log.Data{"dataset": dataset.Name, "file name": rejects.path})
+		}
+	}()
+
+	report, err := c.openChanges(dataset)
+	if err != nil {
+		log.ErrorC("failed to remove previous change report", err, logData)
+		return err
+	}
+	defer func() {
+		if err := report.close(); err != nil {
+			log.ErrorC("failed to write change report", err, log.Data{"dataset": dataset.Name, "file name": report.path})
+		}
+	}()
+
+	// Line each key was first read from, a key read twice is rejected
+	keys := make(map[string]int)
+
+	b := &changeBatch{}
+	err = c.readRows(dataset, result, func(lineNumber int, cols columns, line []string) error {
+		document, err := c.mapRow(dataset, lineNumber, cols, line, rejects, result)
+		if err != nil || document == nil {
+			return err
+		}
+
+		doc, hash, err := normalise(document)
+		if err != nil {
+			return c.reject(rejects, newRowError(err, lineNumber, line), result)
+		}
+
+		// Every row of a course without a subject from the dictionary would
+		// share one key, so none of them can be matched with a stored document
+		if dataset.SubjectColumn != "" && lookup(doc, []string{"subject", "code"}) == nil {
+			rowErr := &RowError{LineNumber: lineNumber, Column: dataset.SubjectColumn, Value: cols.get(line, dataset.SubjectColumn), Reason: "unknown subject", Line: line}
+			log.ErrorC("rejected row", rowErr, log.Data{"dataset": dataset.Name, "line_number": lineNumber})
+			return c.reject(rejects, rowErr, result)
+		}
+
+		key := keyValues(doc, dataset.Key)
+		k := strings.Join(key, "\x00")
+		if first, ok := keys[k]; ok {
+			rowErr := &RowError{LineNumber: lineNumber, Reason: fmt.Sprintf("duplicate key, first read on line %d", first), Line: line}
+			log.ErrorC("rejected row", rowErr, log.Data{"dataset": dataset.Name, "line_number": lineNumber, "key": key})
+			return c.reject(rejects, rowErr, result)
+		}
+		keys[k] = lineNumber
+
+		d, ok := stored[k]
+		switch {
+		case !ok:
+			b.add(store.Change{Document: document}, changeInsert, key, lineNumber, line)
+		case d.hash == hash:
+			d.seen = true
+			result.Changes.Unchanged++
+			return nil
+		default:
+			d.seen = true
+			b.add(store.Change{Key: d.key, Document: document}, changeUpdate, key, lineNumber, line)
+		}
+
+		if len(b.changes) >= c.batchSize() {
+			if err := c.applyBatch(dataset, staging, b, counter, rejects, report, result); err != nil {
+				return err
+			}
+			b = &changeBatch{}
+		}
+
+		return nil
+	}, c.rejectRead(rejects, result))
+	if err != nil {
+		return err
+	}
+
+	if err := c.applyBatch(dataset, staging, b, counter, rejects, report, result); err != nil {
+		return err
+	}
+
+	if err := c.deleteUnseen(dataset, staging, stored, counter, rejects, report, result); err != nil {
+		return err
+	}
+
+	if err := c.checkRejectPercent(rejects, result); err != nil {
+		log.ErrorC("too many rows rejected, live collection has not been replaced", err, logData)
+		return err
+	}
+
+	changes := result.Changes
+	if changes.Inserted+changes.Updated+changes.Deleted == 0 {
+		log.Info("no changes, live collection left in place", log.Data{"dataset": dataset.Name, "release": c.Release, "unchanged": changes.Unchanged})
+
+		if err = s.Drop(dataset.Database, staging); err != nil {
+			log.ErrorC("failed to drop unchanged staging collection", err, logData)
+			return err
+		}
+
+		return nil
+	}
+
+	var count int
+	if dataset.Reference {
+		count, err = s.Count(dataset.Database, staging, nil)
+	} else {
+		count, err = s.Count(dataset.Database, staging, store.Key{"release": c.Release})
+	}
+	if err != nil {
+		log.ErrorC("failed to count documents in staging collection", err, logData)
+		return err
+	}
+
+	expected := len(stored) + changes.Inserted - changes.Deleted
+	if count == 0 || count != expected {
+		err = fmt.Errorf("staging collection %s.%s holds %d documents of the release but %d were expected", dataset.Database, staging, count, expected)
+		log.ErrorC("staging collection failed validation, live collection has not been replaced", err, logData)
+		return err
+	}
+
+	if err = s.Swap(dataset.Database, dataset.Collection); err != nil {
+		log.ErrorC("failed to swap staging collection into place", err, logData)
+		return err
+	}
+
+	log.Info("applied changes", log.Data{
+		"dataset":   dataset.Name,
+		"release":   c.Release,
+		"documents": count,
+		"inserted":  changes.Inserted,
+		"updated":   changes.Updated,
+		"deleted":   changes.Deleted,
+		"unchanged": changes.Unchanged,
+		"rejected":  result.RowsRejected,
+		"report":    report.path,
+	})
+
+	return nil
+}
+
+// storedDocuments reads the documents of the release from the staging
+// collection, keyed by the values of the dataset's key
+func (c *Common) storedDocuments(dataset *Dataset, collection string) (map[string]*storedDocument, error) {
+	query := store.Key{"release": c.Release}
+	if dataset.Reference {
+		query = nil
+	}
+
+	var documents []bson.M
+	if err := c.Store.Find(dataset.Database, collection, query, &documents); err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]*storedDocument)
+	for _, doc := range documents {
+		delete(doc, "_id")
+
+		hash, err := hashDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		key := keyValues(doc, dataset.Key)
+		k := strings.Join(key, "\x00")
+		if _, ok := stored[k]; ok {
+			return nil, fmt.Errorf("more than one stored document has the key %s, a full load is needed", strings.Join(key, ", "))
+		}
+
+		// A missing field of the key matches as nil
+		storeKey := make(store.Key)
+		for _, path := range dataset.Key {
+			storeKey[path] = lookup(doc, strings.Split(path, "."))
+		}
+		if !dataset.Reference {
+			storeKey["release"] = c.Release
+		}
+
+		stored[k] = &storedDocument{key: storeKey, hash: hash}
+	}
+
+	return stored, nil
+}
+
+// deleteUnseen removes every stored document which no row of the file
+// matched, unless rows were rejected
+func (c *Common) deleteUnseen(dataset *Dataset, collection string, stored map[string]*storedDocument, counter chan<- Progress, rejects, report *reportFile, result *Result) error {
+	var unseen []string
+	for k, s := range stored {
+		if !s.seen {
+			unseen = append(unseen, k)
+		}
+	}
+
+	if len(unseen) == 0 {
+		return nil
+	}
+
+	if result.RowsRejected > 0 {
+		result.Changes.Kept = len(unseen)
+		log.Info("rows were rejected, documents missing from the file have been kept", log.Data{"dataset": dataset.Name, "kept": len(unseen), "rejected": result.RowsRejected})
+		return nil
+	}
+
+	sort.Strings(unseen)
+
+	b := &changeBatch{}
+	for _, k := range unseen {
+		b.add(store.Change{Key: stored[k].key}, changeDelete, strings.Split(k, "\x00"), 0, nil)
+
+		if len(b.changes) >= c.batchSize() {
+			if err := c.applyBatch(dataset, collection, b, counter, rejects, report, result); err != nil {
+				return err
+			}
+			b = &changeBatch{}
+		}
+	}
+
+	return c.applyBatch(dataset, collection, b, counter, rejects, report, result)
+}
+
+// changeBatch holds changes waiting to be written along with the key of each
+// and the csv line it was read from, if any
+type changeBatch struct {
+	changes     []store.Change
+	kinds       []string
+	keys        [][]string
+	lineNumbers []int
+	lines       [][]string
+}
+
+func (b *changeBatch) add(change store.Change, kind string, key []string, lineNumber int, line []string) {
+	b.changes = append(b.changes, change)
+	b.kinds = append(b.kinds, kind)
+	b.keys = append(b.keys, key)
+	b.lineNumbers = append(b.lineNumbers, lineNumber)
+	b.lines = append(b.lines, line)
+}
+
+// applyBatch writes a batch of changes in a single bulk write, listing each
+// change made in the change report and rejecting each which could not be made
+func (c *Common) applyBatch(dataset *Dataset, collection string, b *changeBatch, counter chan<- Progress, rejects, report *reportFile, result *Result) error {
+	if len(b.changes) == 0 {
+		return nil
+	}
+
+	_, failed, err := c.Store.ApplyChanges(dataset.Database, collection, b.changes)
+	if err != nil {
+		log.ErrorC("failed to apply batch of changes", err, log.Data{"dataset": dataset.Name, "changes": len(b.changes)})
+		return err
+	}
+
+	failedAt := make(map[int]bool)
+	for _, changeErr := range failed {
+		rowErr := &RowError{Reason: changeErr.Err.Error()}
+		logData := log.Data{"dataset": dataset.Name}
+		if changeErr.Index >= 0 && changeErr.Index < len(b.changes) {
+			failedAt[changeErr.Index] = true
+			rowErr.LineNumber = b.lineNumbers[changeErr.Index]
+			rowErr.Line = b.lines[changeErr.Index]
+			logData["line_number"] = rowErr.LineNumber
+			logData["change"] = b.kinds[changeErr.Index]
+			logData["key"] = b.keys[changeErr.Index]
+		}
+
+		log.ErrorC("failed to apply change", changeErr.Err, logData)
+
+		if err := c.reject(rejects, rowErr, result); err != nil {
+			return err
+		}
+	}
+
+	written := 0
+	for i, kind := range b.kinds {
+		if failedAt[i] {
+			continue
+		}
+
+		switch kind {
+		case changeInsert:
+			result.Changes.Inserted++
+		case changeUpdate:
+			result.Changes.Updated++
+		case changeDelete:
+			result.Changes.Deleted++
+			if err := report.write(append([]string{kind, ""}, b.keys[i]...)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		written++
+		if err := report.write(append([]string{kind, strconv.Itoa(b.lineNumbers[i])}, b.keys[i]...)); err != nil {
+			return err
+		}
+	}
+
+	result.RowsWritten += written
+	counter <- Progress{Dataset: dataset.Name, Count: written}
+
+	return nil
+}
+
+// openChanges removes the change report left by a previous load of the dataset
+func (c *Common) openChanges(dataset *Dataset) (*reportFile, error) {
+	header := []string{"CHANGE", "LINE_NUMBER"}
+	for _, path := range dataset.Key {
+		header = append(header, strings.ToUpper(path))
+	}
+
+	return openReport(c.ChangesLocation, dataset.FileName+".changes"+fileExtension, header)
+}
+
+// normalise returns a document as it will be read back from the store, along
+// with a hash of its fields to compare it with a stored document
+func normalise(document interface{}) (bson.M, [sha1.Size]byte, error) {
+	var doc bson.M
+
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return nil, [sha1.Size]byte{}, err
+	}
+
+	if err = bson.Unmarshal(raw, &doc); err != nil {
+		return nil, [sha1.Size]byte{}, err
+	}
+
+	hash, err := hashDocument(doc)
+	return doc, hash, err
+}
+
+// hashDocument returns a hash of a document's fields. Fields are hashed in
+// order of name, as a document may be read back with them in any order.
+func hashDocument(doc bson.M) ([sha1.Size]byte, error) {
+	raw, err := bson.Marshal(sorted(doc))
+	if err != nil {
+		return [sha1.Size]byte{}, err
+	}
+
+	return sha1.Sum(raw), nil
+}
+
+// sorted returns a value with the fields of each document within it in order
+// of name
+func sorted(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		doc := make(bson.D, len(names))
+		for i, name := range names {
+			doc[i] = bson.DocElem{Name: name, Value: sorted(v[name])}
+		}
+		return doc
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = sorted(v[i])
+		}
+		return values
+	}
+
+	return value
+}
+
+// keyValues returns the value of each field of the key in the document, a
+// path such as subject.code looks inside embedded documents and a missing
+// field has a blank value
+func keyValues(doc bson.M, key []string) []string {
+	values := make([]string, len(key))
+	for i, path := range key {
+		if value := lookup(doc, strings.Split(path, ".")); value != nil {
+			values[i] = fmt.Sprint(value)
+		}
+	}
+
+	return values
+}
+
+// lookup returns the value at a path within a document, nil if it is missing
+func lookup(doc bson.M, path []string) interface{} {
+	value, ok := doc[path[0]]
+	if !ok || len(path) == 1 {
+		return value
+	}
+
+	if embedded, ok := value.(bson.M); ok {
+		return lookup(embedded, path[1:])
+	}
+
+	return nil
+}
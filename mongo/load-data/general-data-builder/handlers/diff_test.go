@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+)
+
+func TestNormaliseMatchesStoredDocument(t *testing.T) {
+	job := &data.JobList{
+		Job:           "Nurses",
+		KISMode:       "1",
+		KISCourseID:   "B700",
+		Order:         2,
+		PublicUKPRN:   "10007800",
+		SubjectObject: &data.SubjectObject{SubjectCode: "CAH02-04-01", SubjectName: "Nursing"},
+		UKPRN:         "10007800",
+	}
+	job.SetRelease("2018")
+
+	doc, hash, err := normalise(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := keyValues(doc, jobListDataset.Key)
+	expected := []string{"10007800", "10007800", "B700", "1", "CAH02-04-01", "2"}
+	if !reflect.DeepEqual(key, expected) {
+		t.Errorf("expected key %q, got %q", expected, key)
+	}
+
+	// A stored document is read back with an id, which is not compared, and
+	// its fields in any order
+	stored := bson.M{"_id": bson.NewObjectId()}
+	for name, value := range doc {
+		stored[name] = value
+	}
+
+	raw, err := bson.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read bson.M
+	if err = bson.Unmarshal(raw, &read); err != nil {
+		t.Fatal(err)
+	}
+	delete(read, "_id")
+
+	storedHash, err := hashDocument(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if storedHash != hash {
+		t.Error("expected stored document to match the row it was loaded from")
+	}
+
+	job.Job = "Midwives"
+	if _, changedHash, _ := normalise(job); changedHash == hash {
+		t.Error("expected a changed row not to match the stored document")
+	}
+}
+
+func TestKeyValuesMissingField(t *testing.T) {
+	doc := bson.M{"ukprn": "10007800"}
+
+	key := keyValues(doc, []string{"ukprn", "subject.code"})
+	if expected := []string{"10007800", ""}; !reflect.DeepEqual(key, expected) {
+		t.Errorf("expected key %q, got %q", expected, key)
+	}
+}
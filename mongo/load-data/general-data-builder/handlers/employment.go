@@ -1,146 +1,76 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateEmployment ...
-func (c *Common) CreateEmployment(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// employmentDataset loads the EMPLOYMENT file into statistics.employment
+var employmentDataset = &Dataset{
+	Name:       "EMPLOYMENT",
+	FileName:   "EMPLOYMENT",
+	Database:   "statistics",
+	Collection: "employment",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "EMPUNAVAILREASON", "EMPPOP",
+		"EMPRESP_RATE", "EMPAGG", "EMPSBJ", "WORKSTUDY", "STUDY", "ASSUNEMP", "BOTH",
+		"NOAVAIL", "WORK",
+	},
+	SubjectColumn: "EMPSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/EMPLOYMENT",
+	Map:           mapEmployment,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapEmployment maps a row of the EMPLOYMENT file to a employment resource
+func mapEmployment(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	employment := &data.Employment{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "EMPUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "EMPAGG") != "" {
+		employment.AggregationLevel, err = cols.atoi(line, "EMPAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		employment := &data.Employment{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[7] != "" {
-			employment.AggregationLevel, err = strconv.Atoi(line[7])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			employment.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[11] != "" {
-			employment.ProportionOfStudentsAssumedToBeUnemployed, err = strconv.Atoi(line[11])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[10] != "" {
-			employment.ProportionOfStudentsInStudy, err = strconv.Atoi(line[10])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[14] != "" {
-			employment.ProportionOfStudentsInWork, err = strconv.Atoi(line[14])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[12] != "" {
-			employment.ProportionOfStudentsInWorkAndStudy, err = strconv.Atoi(line[12])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[9] != "" {
-			employment.ProportionOfStudentsInWorkOrStudy, err = strconv.Atoi(line[9])
-			if err != nil {
-				return err
-			}
-		}
+	if employment.NumberOfStudents, err = cols.number(line, "EMPPOP"); err != nil {
+		return nil, err
+	}
 
-		if line[13] != "" {
-			employment.ProportionOfStudentsNotAvailableForWorkOrStudy, err = strconv.Atoi(line[13])
-			if err != nil {
-				return err
-			}
-		}
+	if employment.ProportionOfStudentsAssumedToBeUnemployed, err = cols.number(line, "ASSUNEMP"); err != nil {
+		return nil, err
+	}
 
-		if line[6] != "" {
-			employment.ResponseRate, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
+	if employment.ProportionOfStudentsInStudy, err = cols.number(line, "STUDY"); err != nil {
+		return nil, err
+	}
 
-		if line[8] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[8])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "employment_resource": employment})
-			}
+	if employment.ProportionOfStudentsInWork, err = cols.number(line, "WORK"); err != nil {
+		return nil, err
+	}
 
-			if subjectObject != nil {
-				employment.SubjectObject = subjectObject
-			}
-		}
+	if employment.ProportionOfStudentsInWorkAndStudy, err = cols.number(line, "BOTH"); err != nil {
+		return nil, err
+	}
 
-		if err := m.AddEmployment(database, collection, employment); err != nil {
-			log.ErrorC("failed to add employment resource", err, log.Data{"line_count": count, "employment_resource": employment})
-			return err
-		}
+	if employment.ProportionOfStudentsInWorkOrStudy, err = cols.number(line, "WORKSTUDY"); err != nil {
+		return nil, err
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if employment.ProportionOfStudentsNotAvailableForWorkOrStudy, err = cols.number(line, "NOAVAIL"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created employment resources", nil)
+	if employment.ResponseRate, err = cols.number(line, "EMPRESP_RATE"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return employment, nil
 }
@@ -1,153 +1,80 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateEntry ...
-func (c *Common) CreateEntry(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// entryDataset loads the ENTRY file into statistics.entry
+var entryDataset = &Dataset{
+	Name:       "ENTRY",
+	FileName:   "ENTRY",
+	Database:   "statistics",
+	Collection: "entry",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "ENTUNAVAILREASON", "ENTPOP",
+		"ENTAGG", "ENTSBJ", "ACCESS", "ALEVEL", "BACC", "DEGREE", "FOUNDTN", "NOQUALS",
+		"OTHER", "OTHERHE",
+	},
+	SubjectColumn: "ENTSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/ENTRY",
+	Map:           mapEntry,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapEntry maps a row of the ENTRY file to a entry resource
+func mapEntry(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	entry := &data.Entry{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "ENTUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "ENTAGG") != "" {
+		entry.AggregationLevel, err = cols.atoi(line, "ENTAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		entry := &data.Entry{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[6] != "" {
-			entry.AggregationLevel, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			entry.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[9] != "" {
-			entry.ProportionOfStudentsWithALevel, err = strconv.Atoi(line[9])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[8] != "" {
-			entry.ProportionOfStudentsWithAccessCourse, err = strconv.Atoi(line[8])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[10] != "" {
-			entry.ProportionOfStudentsWithBaccalaureate, err = strconv.Atoi(line[10])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[11] != "" {
-			entry.ProportionOfStudentsWithDegree, err = strconv.Atoi(line[11])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[12] != "" {
-			entry.ProportionOfStudentsWithFoundation, err = strconv.Atoi(line[12])
-			if err != nil {
-				return err
-			}
-		}
+	if entry.NumberOfStudents, err = cols.number(line, "ENTPOP"); err != nil {
+		return nil, err
+	}
 
-		if line[13] != "" {
-			entry.ProportionOfStudentsWithNoQuals, err = strconv.Atoi(line[13])
-			if err != nil {
-				return err
-			}
-		}
+	if entry.ProportionOfStudentsWithALevel, err = cols.number(line, "ALEVEL"); err != nil {
+		return nil, err
+	}
 
-		if line[15] != "" {
-			entry.ProportionOfStudentsWithOtherHEQuals, err = strconv.Atoi(line[15])
-			if err != nil {
-				return err
-			}
-		}
+	if entry.ProportionOfStudentsWithAccessCourse, err = cols.number(line, "ACCESS"); err != nil {
+		return nil, err
+	}
 
-		if line[14] != "" {
-			entry.ProportionOfStudentsWithOtherQuals, err = strconv.Atoi(line[14])
-			if err != nil {
-				return err
-			}
-		}
+	if entry.ProportionOfStudentsWithBaccalaureate, err = cols.number(line, "BACC"); err != nil {
+		return nil, err
+	}
 
-		if line[7] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[7])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "entry_resource": entry})
-			}
+	if entry.ProportionOfStudentsWithDegree, err = cols.number(line, "DEGREE"); err != nil {
+		return nil, err
+	}
 
-			if subjectObject != nil {
-				entry.SubjectObject = subjectObject
-			}
-		}
+	if entry.ProportionOfStudentsWithFoundation, err = cols.number(line, "FOUNDTN"); err != nil {
+		return nil, err
+	}
 
-		if err := m.AddEntry(database, collection, entry); err != nil {
-			log.ErrorC("failed to add entry resource", err, log.Data{"line_count": count, "entry_resource": entry})
-			return err
-		}
+	if entry.ProportionOfStudentsWithNoQuals, err = cols.number(line, "NOQUALS"); err != nil {
+		return nil, err
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if entry.ProportionOfStudentsWithOtherHEQuals, err = cols.number(line, "OTHERHE"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created entry resources", nil)
+	if entry.ProportionOfStudentsWithOtherQuals, err = cols.number(line, "OTHER"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return entry, nil
 }
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/internal/golden"
+)
+
+// TestGolden loads the fixture csv of every dataset into memory and compares
+// the documents with the dataset's golden file in testdata/golden. Run with
+// -update to rewrite the golden files after a deliberate change to a mapping.
+func TestGolden(t *testing.T) {
+	c, counter, done := newTestCommon(t, filepath.Join("testdata", "golden"))
+	defer done()
+
+	// No fixture row may be rejected
+	c.MaxRejects = 0
+
+	if result := c.Load(CAHCodes, counter); !result.OK() {
+		t.Fatalf("failed to load cah codes: %+v", result)
+	}
+
+	var err error
+	if c.Subjects, err = c.LoadSubjects(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dataset := range append([]*Dataset{CAHCodes}, Datasets...) {
+		t.Run(dataset.Name, func(t *testing.T) {
+			if dataset != CAHCodes {
+				if result := c.Load(dataset, counter); !result.OK() {
+					t.Fatalf("failed to load: %+v", result)
+				}
+			}
+
+			var documents []map[string]interface{}
+			if err := c.Store.Find(dataset.Database, dataset.Collection, nil, &documents); err != nil {
+				t.Fatal(err)
+			}
+
+			golden.Check(t, filepath.Join("testdata", "golden", dataset.FileName+".json"), documents)
+		})
+	}
+}
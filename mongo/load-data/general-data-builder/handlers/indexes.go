@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// Indexes shared by datasets
+var (
+	// courseIndexes serve the course builder's lookups of a course's documents
+	courseIndexes = []store.Index{
+		{Key: []string{"public_ukprn", "kis_course_id", "kis_mode", "release"}},
+	}
+
+	// codeIndexes identify each code of reference data
+	codeIndexes = []store.Index{
+		{Key: []string{"code"}, Unique: true},
+	}
+)
+
+// ensureIndexes builds the dataset's indexes on the collection before any
+// document is written, so a document breaking a unique index is rejected.
+// Indexes are only built in mongo.
+func (c *Common) ensureIndexes(dataset *Dataset, collection string, result *Result) error {
+	if len(dataset.Indexes) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := c.Store.EnsureIndexes(dataset.Database, collection, dataset.Indexes)
+	result.IndexDuration = time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		log.ErrorC("failed to ensure indexes", err, log.Data{"dataset": dataset.Name, "collection": collection})
+	}
+
+	return err
+}
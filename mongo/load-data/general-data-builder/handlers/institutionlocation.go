@@ -1,78 +1,71 @@
 package handlers
 
 import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-
-	"github.com/ONSdigital/go-ns/log"
 	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
 )
 
-// CreateInstitutionLocation ...
-func (c *Common) CreateInstitutionLocation(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
+// institutionLocationDataset loads the LOCATION file into institutions.locations
+var institutionLocationDataset = &Dataset{
+	Name:       "LOCATION",
+	FileName:   "LOCATION",
+	Database:   "institutions",
+	Collection: "locations",
+	Columns: []string{
+		"UKPRN", "ACCOMURL", "ACCOMURLW", "LOCID", "LOCNAME", "LOCNAMEW", "LATITUDE",
+		"LONGITUDE", "LOCUKPRN", "LOCCOUNTRY", "SUURL", "SUURLW",
+	},
+	Required: []string{"UKPRN", "LOCID"},
+	Codes:    map[string][]string{"LOCCOUNTRY": countryCodes},
+	Key:      []string{"ukprn", "location_id"},
+	Indexes: []store.Index{
+		{Key: []string{"ukprn", "location_id", "release"}, Unique: true},
+		{Key: []string{"$2dsphere:point"}},
+	},
+	XMLPath: "INSTITUTION/LOCATION",
+	Map:     mapInstitutionLocation,
+}
 
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
+// mapInstitutionLocation maps a row of the LOCATION file to a institution location resource
+func mapInstitutionLocation(cols columns, line []string, _ *data.SubjectObject) (document interface{}, err error) {
+	institutionLocation := &data.InstitutionLocation{
+		AccommodationURL:      cols.get(line, "ACCOMURL"),
+		AccommodationURLWelsh: cols.get(line, "ACCOMURLW"),
+		CountryCode:           cols.get(line, "LOCCOUNTRY"),
+		Latitude:              cols.get(line, "LATITUDE"),
+		LocationID:            cols.get(line, "LOCID"),
+		LocationName:          cols.get(line, "LOCNAME"),
+		LocationNameWelsh:     cols.get(line, "LOCNAMEW"),
+		LocationUKPRN:         cols.get(line, "LOCUKPRN"),
+		Longitude:             cols.get(line, "LONGITUDE"),
+		StudentUnionURL:       cols.get(line, "SUURL"),
+		StudentUnionURLWelsh:  cols.get(line, "SUURLW"),
+		UKPRN:                 cols.get(line, "UKPRN"),
 	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+	if institutionLocation.Point, err = mapPoint(cols, line, "LATITUDE", "LONGITUDE"); err != nil {
+		return nil, err
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		institutionLocation := &data.InstitutionLocation{
-			AccommodationURL:      line[1],
-			AccommodationURLWelsh: line[2],
-			CountryCode:           line[9],
-			Latitude:              line[6],
-			LocationID:            line[3],
-			LocationName:          line[4],
-			LocationNameWelsh:     line[5],
-			LocationUKPRN:         line[8],
-			Longitude:             line[7],
-			StudentUnionURL:       line[10],
-			StudentUnionURLWelsh:  line[11],
-			UKPRN:                 line[0],
-		}
+	return institutionLocation, nil
+}
 
-		if err := m.AddInstitutionLocation(database, collection, institutionLocation); err != nil {
-			log.ErrorC("failed to add institution location resource", err, log.Data{"line_count": count, "institution_location_resource": institutionLocation})
-			return err
-		}
+// mapPoint maps the latitude and longitude columns of a row to a GeoJSON
+// point, returning nil if neither is published
+func mapPoint(cols columns, line []string, latitudeColumn, longitudeColumn string) (*data.Point, error) {
+	if cols.get(line, latitudeColumn) == "" && cols.get(line, longitudeColumn) == "" {
+		return nil, nil
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	latitude, err := cols.coordinate(line, latitudeColumn, 90)
+	if err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created institution location resources", nil)
+	longitude, err := cols.coordinate(line, longitudeColumn, 180)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return data.NewPoint(latitude, longitude), nil
 }
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapPoint(t *testing.T) {
+	cols := fixedColumns([]string{"LATITUDE", "LONGITUDE"})
+
+	point, err := mapPoint(cols, []string{"51.453256", "-0.963443"}, "LATITUDE", "LONGITUDE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []float64{-0.963443, 51.453256}; point == nil || point.Type != "Point" || !reflect.DeepEqual(point.Coordinates, expected) {
+		t.Errorf("expected point at %v, got %+v", expected, point)
+	}
+
+	if point, err = mapPoint(cols, []string{"", ""}, "LATITUDE", "LONGITUDE"); err != nil || point != nil {
+		t.Errorf("expected no point for a blank location, got %+v, %v", point, err)
+	}
+
+	for _, line := range [][]string{
+		{"51.4", ""},
+		{"north", "-0.96"},
+		{"91", "-0.96"},
+		{"51.4", "180.5"},
+		{"NaN", "-0.96"},
+	} {
+		if _, err = mapPoint(cols, line, "LATITUDE", "LONGITUDE"); err == nil {
+			t.Errorf("expected %q to be rejected", line)
+		}
+	}
+}
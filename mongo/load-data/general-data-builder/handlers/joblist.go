@@ -1,97 +1,46 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateJobList ...
-func (c *Common) CreateJobList(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// jobListDataset loads the JOBLIST file into statistics.job-list
+var jobListDataset = &Dataset{
+	Name:       "JOBLIST",
+	FileName:   "JOBLIST",
+	Database:   "statistics",
+	Collection: "job-list",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "COMSBJ", "JOB", "PERC", "ORDER",
+	},
+	SubjectColumn: "COMSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           []string{"public_ukprn", "ukprn", "kis_course_id", "kis_mode", "subject.code", "order"},
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/JOBLIST/JOB",
+	Map:           mapJobList,
+}
 
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
+// mapJobList maps a row of the JOBLIST file to a job list resource
+func mapJobList(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	jobList := &data.JobList{
+		Job:           cols.get(line, "JOB"),
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		SubjectObject: subject,
 	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+	if jobList.PercentageOfStudents, err = cols.number(line, "PERC"); err != nil {
+		return nil, err
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "ORDER") != "" {
+		jobList.Order, err = cols.atoi(line, "ORDER")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		jobList := &data.JobList{
-			Job:         line[5],
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-		}
-
-		if line[6] != "" {
-			jobList.PercentageOfStudents, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[7] != "" {
-			jobList.Order, err = strconv.Atoi(line[7])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[4] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[4])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "job_list_resource": jobList})
-			}
-
-			if subjectObject != nil {
-				jobList.SubjectObject = subjectObject
-			}
-		}
-
-		if err := m.AddJobList(database, collection, jobList); err != nil {
-			log.ErrorC("failed to add job list resource", err, log.Data{"line_count": count, "job_list_resource": jobList})
-			return err
-		}
-
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
+			return nil, err
 		}
 	}
 
-	counter <- count
-	log.Info("created job list resources", nil)
-
-	return nil
+	return jobList, nil
 }
@@ -1,118 +1,59 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateLongitudinalEducationOutcomes ...
-func (c *Common) CreateLongitudinalEducationOutcomes(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from institution collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// leoDataset loads the LEO file into statistics.leo
+var leoDataset = &Dataset{
+	Name:       "LEO",
+	FileName:   "LEO",
+	Database:   "statistics",
+	Collection: "leo",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "LEOUNAVAILREASON", "LEOPOP",
+		"LEOAGG", "LEOSBJ", "LEOLQ", "LEOMED", "LEOUQ",
+	},
+	SubjectColumn: "LEOSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/LEO",
+	Map:           mapLEO,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapLEO maps a row of the LEO file to a leo course statistic resource
+func mapLEO(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	leoData := &data.Leo{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "LEOUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "LEOAGG") != "" {
+		leoData.AggregationLevel, err = cols.atoi(line, "LEOAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		leoData := &data.Leo{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[6] != "" {
-			leoData.AggregationLevel, err = strconv.Atoi(line[6])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[10] != "" {
-			leoData.HigherQuartileRange, err = strconv.Atoi(line[10])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[8] != "" {
-			leoData.LowerQuartileRange, err = strconv.Atoi(line[8])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[9] != "" {
-			leoData.Median, err = strconv.Atoi(line[9])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			leoData.NumberOfGraduates, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[7] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[7])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "leo_course_statistic_resource": leoData})
-			}
-
-			if subjectObject != nil {
-				leoData.SubjectObject = subjectObject
-			}
-		}
+	if leoData.HigherQuartileRange, err = cols.number(line, "LEOUQ"); err != nil {
+		return nil, err
+	}
 
-		if err := m.AddLEOCourseStatistic(database, collection, leoData); err != nil {
-			log.ErrorC("failed to add leo course statistic resource", err, log.Data{"line_count": count, "leo_course_statistic_resource": leoData})
-			return err
-		}
+	if leoData.LowerQuartileRange, err = cols.number(line, "LEOLQ"); err != nil {
+		return nil, err
+	}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+	if leoData.Median, err = cols.number(line, "LEOMED"); err != nil {
+		return nil, err
 	}
 
-	counter <- count
-	log.Info("created leo course statistic resources", nil)
+	if leoData.NumberOfGraduates, err = cols.number(line, "LEOPOP"); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return leoData, nil
 }
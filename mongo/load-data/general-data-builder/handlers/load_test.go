@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// newTestCommon returns a Common loading release 2019 of the csv files in dir
+// into a memory store in batches of 2, writing reject files and change reports
+// to a temporary directory, and a counter draining the progress of each load.
+// The returned function removes the directory and stops the counter.
+func newTestCommon(t *testing.T, dir string) (*Common, chan<- Progress, func()) {
+	t.Helper()
+
+	reports, err := ioutil.TempDir("", "reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := source.Open(dir)
+	if err != nil {
+		os.RemoveAll(reports)
+		t.Fatal(err)
+	}
+
+	counter := make(chan Progress)
+	go func() {
+		for range counter {
+		}
+	}()
+
+	c := &Common{
+		Store:            store.NewMemory(),
+		Source:           src,
+		Release:          "2019",
+		BatchSize:        2,
+		RejectsLocation:  reports,
+		ChangesLocation:  reports,
+		MaxRejects:       -1,
+		MaxRejectPercent: -1,
+		Subjects:         Subjects{},
+	}
+
+	return c, counter, func() {
+		close(counter)
+		src.Close()
+		os.RemoveAll(reports)
+	}
+}
+
+func TestLoadIntoMemory(t *testing.T) {
+	c, counter, done := newTestCommon(t, "testdata")
+	defer done()
+
+	for _, release := range []string{"2018", "2019", "2019"} {
+		c.Release = release
+		if result := c.Load(nssDataset, counter); !result.OK() {
+			t.Fatalf("failed to load release %s: %+v", release, result)
+		}
+	}
+
+	// Reloading a release replaces it and leaves the others in place
+	for _, release := range []string{"2018", "2019"} {
+		count, err := c.Store.Count(nssDataset.Database, nssDataset.Collection, store.Key{"release": release})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != 3 {
+			t.Errorf("expected 3 documents of release %s, got %d", release, count)
+		}
+	}
+
+	if err := c.Store.Rollback(nssDataset.Database, nssDataset.Collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, _ := c.Store.Count(nssDataset.Database, nssDataset.Collection, nil); count != 6 {
+		t.Errorf("expected the previous generation to hold both releases, got %d documents", count)
+	}
+}
+
+func TestDiffIntoMemory(t *testing.T) {
+	c, counter, done := newTestCommon(t, "testdata")
+	defer done()
+
+	c.Subjects = Subjects{
+		"CAH10-01-01": {SubjectCode: "CAH10-01-01", SubjectName: "Engineering"},
+		"CAH02-04-01": {SubjectCode: "CAH02-04-01", SubjectName: "Nursing"},
+	}
+
+	if result := c.Load(nssDataset, counter); !result.OK() {
+		t.Fatalf("failed to load: %+v", result)
+	}
+
+	dir, err := ioutil.TempDir("", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Republish the file with the first row changed, the last removed and a
+	// new row added
+	raw, err := ioutil.ReadFile(filepath.Join("testdata", "NSS.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.SplitAfter(string(raw), "\n")
+	republished := lines[0] + strings.Replace(lines[1], ",50,70,", ",55,70,", 1) + lines[2] + strings.Replace(lines[2], "U5678", "U9999", 1)
+	if err = ioutil.WriteFile(filepath.Join(dir, "NSS.csv"), []byte(republished), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Source, err = source.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Source.Close()
+
+	result := c.Diff(nssDataset, counter)
+	if !result.OK() {
+		t.Fatalf("failed to diff: %+v", result)
+	}
+
+	if expected := (Changes{Inserted: 1, Updated: 1, Deleted: 1, Unchanged: 1}); *result.Changes != expected {
+		t.Errorf("expected changes %+v, got %+v", expected, *result.Changes)
+	}
+
+	var stored []map[string]interface{}
+	if err = c.Store.Find(nssDataset.Database, nssDataset.Collection, store.Key{"release": "2019"}, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	courses := make(map[string]interface{})
+	for _, doc := range stored {
+		courses[doc["kis_course_id"].(string)] = doc["number_of_students"]
+	}
+
+	if expected := map[string]interface{}{"U1234": 55, "U5678": 51, "U9999": 51}; !reflect.DeepEqual(courses, expected) {
+		t.Errorf("expected the number of students of each course to be %v, got %v", expected, courses)
+	}
+
+	// Diffing the same file again changes nothing and leaves no staging
+	// collection behind
+	if result = c.Diff(nssDataset, counter); !result.OK() || *result.Changes != (Changes{Unchanged: 3}) {
+		t.Errorf("expected a second diff to leave every document unchanged, got %+v %+v", result, result.Changes)
+	}
+
+	if count, _ := c.Store.Count(nssDataset.Database, store.Staging(nssDataset.Collection), nil); count != 0 {
+		t.Errorf("expected the unchanged staging collection to be dropped, got %d documents", count)
+	}
+
+	// Rows without a subject from the dictionary cannot be told apart by key
+	noSubject := strings.Replace(lines[1], "CAH10-01-01", "", 1) + strings.Replace(lines[2], "CAH02-04-01", "CAH99-99-99", 1)
+	if err = ioutil.WriteFile(filepath.Join(dir, "NSS.csv"), []byte(republished+noSubject), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result = c.Diff(nssDataset, counter)
+	if !result.OK() || result.RowsRejected != 2 {
+		t.Fatalf("expected the 2 rows without a subject to be rejected, got %+v", result)
+	}
+
+	for _, problem := range result.Problems {
+		if problem.Reason != "unknown subject" {
+			t.Errorf("expected rows without a subject to be rejected as an unknown subject, got %+v", problem)
+		}
+	}
+}
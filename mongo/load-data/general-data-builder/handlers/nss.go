@@ -1,124 +1,80 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-	"strconv"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateNSS ...
-func (c *Common) CreateNSS(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// nssDataset loads the NSS file into statistics.nss
+var nssDataset = &Dataset{
+	Name:       "NSS",
+	FileName:   "NSS",
+	Database:   "statistics",
+	Collection: "nss",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "NSSUNAVAILREASON", "NSSPOP",
+		"NSSRESP_RATE", "NSSAGG", "NSSSBJ", "Q1", "Q2", "Q3", "Q4", "Q5", "Q6", "Q7",
+		"Q8", "Q9", "Q10", "Q11", "Q12", "Q13", "Q14", "Q15", "Q16", "Q17", "Q18", "Q19",
+		"Q20", "Q21", "Q22", "Q23", "Q24", "Q25", "Q26", "Q27",
+	},
+	SubjectColumn: "NSSSBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/NSS",
+	Map:           mapNSS,
+}
 
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+// mapNSS maps a row of the NSS file to a nss resource
+func mapNSS(cols columns, line []string, subject *data.SubjectObject) (document interface{}, err error) {
+	nss := &data.NSS{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		Unavailable:   cols.get(line, "NSSUNAVAILREASON"),
+		SubjectObject: subject,
 	}
 
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	if cols.get(line, "NSSAGG") != "" {
+		nss.AggregationLevel, err = cols.atoi(line, "NSSAGG")
 		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		nss := &data.NSS{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-			Unavailable: line[4],
-		}
-
-		if line[7] != "" {
-			nss.AggregationLevel, err = strconv.Atoi(line[7])
-			if err != nil {
-				return err
-			}
-		}
-
-		if line[5] != "" {
-			nss.NumberOfStudents, err = strconv.Atoi(line[5])
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
+	}
 
-		if line[6] != "" {
-			nss.ResponseRate, err = strconv.Atoi(line[6])
-		}
-
-		if line[8] != "" {
-			subjectObject, err := m.GetCAHCode("courses", "cah-codes", line[8])
-			if err != nil {
-				log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "nss_resource": nss})
-			}
-
-			if subjectObject != nil {
-				nss.SubjectObject = subjectObject
-			}
-		}
+	if nss.NumberOfStudents, err = cols.number(line, "NSSPOP"); err != nil {
+		return nil, err
+	}
 
-		var surveys = []*data.Survey{}
-		number := 9
+	if nss.ResponseRate, err = cols.number(line, "NSSRESP_RATE"); err != nil {
+		return nil, err
+	}
 
-		for k, v := range data.NSSQuestions {
-			if line[number] != "" {
-				proportionOfStudentsAgree, err := strconv.Atoi(line[number])
-				if err != nil {
-					return err
-				}
+	if nss.Surveys, err = mapSurveys(cols, line, data.NSSQuestions); err != nil {
+		return nil, err
+	}
 
-				survey := &data.Survey{
-					Number:                    k,
-					ProportionOfStudentsAgree: proportionOfStudentsAgree,
-					Question:                  v,
-				}
+	return nss, nil
+}
 
-				surveys = append(surveys, survey)
-			}
-			number++
+// mapSurveys maps the survey columns of a row to the results for each question,
+// keeping the questions in the order they are listed
+func mapSurveys(cols columns, line []string, questions []data.Question) (surveys []*data.Survey, err error) {
+	for _, question := range questions {
+		if cols.get(line, question.Column) == "" {
+			continue
 		}
 
-		nss.Surveys = surveys
-
-		if err := m.AddNSS(database, collection, nss); err != nil {
-			log.ErrorC("failed to add nss resource", err, log.Data{"line_count": count, "nss_resource": nss})
-			return err
+		proportionOfStudentsAgree, err := cols.atoi(line, question.Column)
+		if err != nil {
+			return nil, err
 		}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+		surveys = append(surveys, &data.Survey{
+			Number:                    question.Number,
+			ProportionOfStudentsAgree: proportionOfStudentsAgree,
+			Question:                  question.Text,
+		})
 	}
 
-	counter <- count
-	log.Info("created nss resources", nil)
-
-	return nil
+	return surveys, nil
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+)
+
+// readFixture maps every row of the dataset's csv file in testdata
+func readFixture(t *testing.T, dataset *Dataset) []interface{} {
+	fileName := dataset.FileName
+
+	csvFile, err := os.Open("testdata/" + fileName + fileExtension)
+	if err != nil {
+		t.Fatalf("failed to open fixture %s: %v", fileName, err)
+	}
+	defer csvFile.Close()
+
+	csvReader := csv.NewReader(csvFile)
+	cols, _, _, err := readHeader(csvReader, fileName, dataset.Columns)
+	if err != nil {
+		t.Fatalf("failed to map header of fixture %s: %v", fileName, err)
+	}
+
+	var documents []interface{}
+	for {
+		line, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read fixture %s: %v", fileName, err)
+		}
+
+		document, err := dataset.Map(cols, line, nil)
+		if err != nil {
+			t.Fatalf("failed to map row of fixture %s: %v", fileName, err)
+		}
+		documents = append(documents, document)
+	}
+
+	return documents
+}
+
+func TestNSSMappingIsDeterministic(t *testing.T) {
+	first := readFixture(t, nssDataset)
+	second := readFixture(t, nssDataset)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("loading the NSS fixture twice produced different documents")
+	}
+
+	// Each question in the fixture holds 60 + question number (+ row index)
+	for row, document := range first {
+		nss := document.(*data.NSS)
+		previous := 0
+		for _, survey := range nss.Surveys {
+			if survey.Number <= previous {
+				t.Errorf("row %d: question %d listed after question %d", row, survey.Number, previous)
+			}
+			previous = survey.Number
+
+			if want := 60 + survey.Number + row; survey.ProportionOfStudentsAgree != want {
+				t.Errorf("row %d: question %d has %d%% agree, want %d%%", row, survey.Number, survey.ProportionOfStudentsAgree, want)
+			}
+
+			if want := data.NSSQuestions[survey.Number-1].Text; survey.Question != want {
+				t.Errorf("row %d: question %d has text %q, want %q", row, survey.Number, survey.Question, want)
+			}
+		}
+	}
+
+	if got := len(first[1].(*data.NSS).Surveys); got != 25 {
+		t.Errorf("expected blank questions to be left out, got %d surveys want 25", got)
+	}
+
+	if got := len(first[2].(*data.NSS).Surveys); got != 0 {
+		t.Errorf("expected no surveys for unpublished row, got %d", got)
+	}
+}
+
+func TestNHSNSSMappingIsDeterministic(t *testing.T) {
+	first := readFixture(t, nhsNSSDataset)
+	second := readFixture(t, nhsNSSDataset)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("loading the NHSNSS fixture twice produced different documents")
+	}
+
+	surveys := first[0].(*data.NHSNSS).Surveys
+	if len(surveys) != len(data.NHSNSSQuestions) {
+		t.Fatalf("got %d surveys, want %d", len(surveys), len(data.NHSNSSQuestions))
+	}
+
+	for i, survey := range surveys {
+		question := data.NHSNSSQuestions[i]
+		if survey.Number != question.Number || survey.Question != question.Text || survey.ProportionOfStudentsAgree != 80+question.Number {
+			t.Errorf("survey %d does not match question %d: %+v", i, question.Number, survey)
+		}
+	}
+}
+
+func TestTariffMappingIsDeterministic(t *testing.T) {
+	first := readFixture(t, tariffDataset)
+	second := readFixture(t, tariffDataset)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("loading the TARIFF fixture twice produced different documents")
+	}
+
+	// Each band in the fixture holds its position in the list of bands (+ row index)
+	for row, document := range first {
+		tariff := document.(*data.Tariff)
+		for i, stats := range tariff.Tariffs {
+			band := data.TariffBands[i]
+			if stats.Code != band.Code || stats.Description != band.Description || stats.ProportionOfEntrants != i+row {
+				t.Errorf("row %d: tariff %d does not match band %s: %+v", row, i, band.Code, stats)
+			}
+		}
+	}
+
+	if got := len(first[1].(*data.Tariff).Tariffs); got != 11 {
+		t.Errorf("expected blank bands to be left out, got %d tariffs want 11", got)
+	}
+}
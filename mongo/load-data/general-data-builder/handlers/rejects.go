@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Default limits on the rows a dataset may reject before its load is abandoned
+const (
+	DefaultMaxRejects       = 100
+	DefaultMaxRejectPercent = 1.0
+)
+
+var rejectsHeader = []string{"LINE_NUMBER", "COLUMN", "VALUE", "REASON", "LINE"}
+
+// reportFile is a csv file written alongside a load, such as the rows a
+// dataset rejected; it is only created once the first record is written
+type reportFile struct {
+	path   string
+	header []string
+	file   *os.File
+	writer *csv.Writer
+}
+
+// openReport removes the report left by a previous load
+func openReport(location, name string, header []string) (*reportFile, error) {
+	path := filepath.Join(location, name)
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &reportFile{path: path, header: header}, nil
+}
+
+// openRejects removes the reject file left by a previous load of the dataset
+func (c *Common) openRejects(dataset *Dataset) (*reportFile, error) {
+	return openReport(c.RejectsLocation, dataset.FileName+".rejects"+fileExtension, rejectsHeader)
+}
+
+func (r *reportFile) write(record []string) error {
+	if r.writer == nil {
+		if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+			return err
+		}
+
+		file, err := os.Create(r.path)
+		if err != nil {
+			return err
+		}
+
+		r.file = file
+		r.writer = csv.NewWriter(file)
+		if err = r.writer.Write(r.header); err != nil {
+			return err
+		}
+	}
+
+	return r.writer.Write(record)
+}
+
+func (r *reportFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	r.writer.Flush()
+	if err := r.writer.Error(); err != nil {
+		r.file.Close()
+		return err
+	}
+
+	return r.file.Close()
+}
+
+// joinLine writes the fields of a csv row back out as they would appear in the file
+func joinLine(line []string) (string, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(line); err != nil {
+		return "", err
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+}
+
+// reject records a row which could not be loaded, returning an error once the
+// dataset has rejected more rows than allowed
+func (c *Common) reject(rejects *reportFile, rowErr *RowError, result *Result) error {
+	result.addProblem(rowErr)
+
+	line, err := joinLine(rowErr.Line)
+	if err != nil {
+		return err
+	}
+
+	if err = rejects.write([]string{strconv.Itoa(rowErr.LineNumber), rowErr.Column, rowErr.Value, rowErr.Reason, line}); err != nil {
+		return err
+	}
+
+	if c.MaxRejects >= 0 && result.RowsRejected > c.MaxRejects {
+		return fmt.Errorf("rejected more than %d rows, see %s", c.MaxRejects, rejects.path)
+	}
+
+	return nil
+}
+
+// rejectRead returns a function rejecting each row of the dataset's file
+// which could not be read, counting it as read
+func (c *Common) rejectRead(rejects *reportFile, result *Result) func(rowErr *RowError) error {
+	return func(rowErr *RowError) error {
+		result.RowsRead++
+		return c.reject(rejects, rowErr, result)
+	}
+}
+
+// checkRejectPercent returns an error if the dataset rejected a larger
+// percentage of the rows read than allowed
+func (c *Common) checkRejectPercent(rejects *reportFile, result *Result) error {
+	if c.MaxRejectPercent < 0 || result.RowsRejected == 0 {
+		return nil
+	}
+
+	percent := float64(result.RowsRejected) * 100 / float64(result.RowsRead)
+	if percent > c.MaxRejectPercent {
+		return fmt.Errorf("rejected %.2f%% of rows, more than the limit of %.2f%%, see %s", percent, c.MaxRejectPercent, rejects.path)
+	}
+
+	return nil
+}
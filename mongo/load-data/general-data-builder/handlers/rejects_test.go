@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+)
+
+func TestRejectWritesOriginalLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rejects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Common{RejectsLocation: dir, MaxRejects: 1, MaxRejectPercent: -1}
+	rejects, err := c.openRejects(salaryDataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &Result{RowsRead: 3}
+	line := []string{"10000001", "a, quoted \"value\"", "x"}
+	if err = c.reject(rejects, &RowError{LineNumber: 4, Column: "SALAGG", Value: "x", Reason: "not a whole number", Line: line}, result); err != nil {
+		t.Fatalf("expected first reject to be within the limit, got %v", err)
+	}
+
+	if err = c.reject(rejects, &RowError{LineNumber: 7, Reason: "duplicate key", Line: line}, result); err == nil {
+		t.Error("expected an error once more rows were rejected than allowed")
+	}
+
+	if err = rejects.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	csvFile, err := os.Open(filepath.Join(dir, "SALARY.rejects.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer csvFile.Close()
+
+	rows, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		rejectsHeader,
+		{"4", "SALAGG", "x", "not a whole number", `10000001,"a, quoted ""value""",x`},
+		{"7", "", "", "duplicate key", `10000001,"a, quoted ""value""",x`},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got reject file %q, want %q", rows, want)
+	}
+
+	if result.RowsRejected != 2 || len(result.Problems) != 2 {
+		t.Errorf("got %d rows rejected and %d problems, want 2 of each", result.RowsRejected, len(result.Problems))
+	}
+}
+
+func TestCheckRejectPercent(t *testing.T) {
+	c := &Common{MaxRejects: -1, MaxRejectPercent: 10}
+	rejects := &reportFile{path: "SALARY.rejects.csv"}
+
+	if err := c.checkRejectPercent(rejects, &Result{RowsRead: 100, RowsRejected: 10}); err != nil {
+		t.Errorf("expected 10%% of rows to be within the limit, got %v", err)
+	}
+
+	if err := c.checkRejectPercent(rejects, &Result{RowsRead: 100, RowsRejected: 11}); err == nil {
+		t.Error("expected an error when 11% of rows were rejected")
+	}
+
+	c.MaxRejectPercent = -1
+	if err := c.checkRejectPercent(rejects, &Result{RowsRead: 1, RowsRejected: 1}); err != nil {
+		t.Errorf("expected no limit when max reject percent is negative, got %v", err)
+	}
+}
+
+func TestReadRowsRejectsMalformedRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rows := "1,BA,U,Bachelor of Arts\n2,BSc,U\n3,B\"A,U,Bachelor of Arts\n4,MA,P,Master of Arts,x\n5,MSc,P,Master of Science\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, "kisaims.csv"), []byte(rows), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := source.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read []int
+	var rejected []*RowError
+	err = (&Common{Source: src}).readRows(qualificationDataset, nil, func(lineNumber int, _ columns, _ []string) error {
+		read = append(read, lineNumber)
+		return nil
+	}, func(rowErr *RowError) error {
+		rejected = append(rejected, rowErr)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected malformed rows not to stop the read, got %v", err)
+	}
+
+	if !reflect.DeepEqual(read, []int{1, 5}) {
+		t.Errorf("got rows %v read, want 1 and 5", read)
+	}
+
+	var reasons []string
+	for _, rowErr := range rejected {
+		reasons = append(reasons, strconv.Itoa(rowErr.LineNumber)+": "+rowErr.Reason)
+	}
+
+	want := []string{"2: expected 4 fields, found 3", "3: " + csv.ErrBareQuote.Error(), "4: expected 4 fields, found 5"}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("got rejected rows %q, want %q", reasons, want)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+)
+
+// Statuses a dataset can finish a run with
+const (
+	StatusLoaded  = "loaded"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	StatusValid   = "valid"
+	StatusInvalid = "invalid"
+)
+
+// Result is the outcome of loading a dataset
+type Result struct {
+	Dataset         string         `json:"dataset"`
+	Status          string         `json:"status"`
+	RowsRead        int            `json:"rows_read"`
+	RowsWritten     int            `json:"rows_written"`
+	RowsRejected    int            `json:"rows_rejected"`
+	Duration        time.Duration  `json:"duration"`
+	IndexDuration   time.Duration  `json:"index_duration"` // time taken to ensure the collection's indexes
+	Error           string         `json:"error,omitempty"`
+	UnknownCAHCodes map[string]int `json:"unknown_cah_codes,omitempty"` // CAH code -> number of rows loaded without a subject
+	ExtraColumns    []string       `json:"extra_columns,omitempty"`     // columns of the header row which are not loaded
+	Problems        []*RowError    `json:"problems,omitempty"`          // first rows to fail validation
+	Changes         *Changes       `json:"changes,omitempty"`           // documents changed by a diff
+}
+
+// MarshalJSON writes the durations in a readable form, e.g. 1m30.5s
+func (r *Result) MarshalJSON() ([]byte, error) {
+	type result Result
+
+	return json.Marshal(struct {
+		*result
+		Duration      string `json:"duration"`
+		IndexDuration string `json:"index_duration"`
+	}{
+		result:        (*result)(r),
+		Duration:      r.Duration.String(),
+		IndexDuration: r.IndexDuration.String(),
+	})
+}
+
+// Skipped returns the result for a dataset which was not loaded
+func Skipped(dataset *Dataset, reason string) *Result {
+	return &Result{
+		Dataset: dataset.Name,
+		Status:  StatusSkipped,
+		Error:   reason,
+	}
+}
+
+// Failed reports whether the dataset failed to load
+func (r *Result) Failed() bool {
+	return r.Status == StatusFailed
+}
+
+// OK reports whether the dataset was loaded or passed validation
+func (r *Result) OK() bool {
+	return r.Status == StatusLoaded || r.Status == StatusValid
+}
+
+func (r *Result) finish(start time.Time, err error) {
+	r.Duration = time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		r.Status = StatusFailed
+		r.Error = err.Error()
+		return
+	}
+
+	r.Status = StatusLoaded
+}
+
+func (r *Result) finishValidation(start time.Time, err error) {
+	r.Duration = time.Since(start).Round(time.Millisecond)
+
+	switch {
+	case err != nil:
+		r.Status = StatusFailed
+		r.Error = err.Error()
+	case r.RowsRejected > 0:
+		r.Status = StatusInvalid
+	default:
+		r.Status = StatusValid
+	}
+}
+
+func (r *Result) addProblem(rowErr *RowError) {
+	r.RowsRejected++
+	if len(r.Problems) < maxProblems {
+		r.Problems = append(r.Problems, rowErr)
+	}
+}
+
+func (r *Result) addUnknownCAHCode(code string) {
+	if r.UnknownCAHCodes == nil {
+		r.UnknownCAHCodes = make(map[string]int)
+	}
+
+	r.UnknownCAHCodes[code]++
+}
+
+// WriteReport writes the results as a table followed by a JSON summary, both
+// naming the source the csv files were read from
+func WriteReport(w io.Writer, src *source.Source, results []*Result) error {
+	return src.WriteReport(w, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "DATASET\tSTATUS\tREAD\tWRITTEN\tREJECTED\tUNKNOWN CAH CODES\tINDEXES\tDURATION\tERROR")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n", r.Dataset, r.Status, r.RowsRead, r.RowsWritten, r.RowsRejected, len(r.UnknownCAHCodes), r.IndexDuration, r.Duration, r.Error)
+		}
+	}, results)
+}
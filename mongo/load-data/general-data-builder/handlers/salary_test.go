@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+)
+
+func TestSalaryKeepsPublishedZeros(t *testing.T) {
+	cols := fixedColumns(salaryDataset.Columns)
+	line := []string{
+		"10007800", "10007800", "B700", "1", "", "0",
+		"", "14", "CAH02-04-01", "", "", "", "0", "21000", "",
+		"", "", "",
+	}
+
+	document, err := mapSalary(cols, line, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salary := document.(*data.Salary)
+
+	if salary.NumberOfStudents == nil || *salary.NumberOfStudents != 0 {
+		t.Errorf("expected published zero SALPOP to be kept, got %v", salary.NumberOfStudents)
+	}
+
+	if salary.ResponseRate != nil {
+		t.Errorf("expected blank SALRESP_RATE to be absent, got %d", *salary.ResponseRate)
+	}
+
+	stats := salary.SubjectSalarySixMonthsAfterGraduation
+	if stats == nil || stats.LowerQuartile == nil || *stats.LowerQuartile != 0 || stats.Median == nil || *stats.Median != 21000 || stats.UpperQuartile != nil {
+		t.Errorf("expected lower quartile 0, median 21000 and no upper quartile, got %+v", stats)
+	}
+
+	if salary.SubjectSalaryFortyMonthsAfterGraduation != nil || salary.InstitutionCourseSalarySixMonthsAfterGraduation != nil {
+		t.Error("expected salaries with no published values to be absent")
+	}
+}
@@ -1,79 +1,34 @@
 package handlers
 
-import (
-	"bufio"
-	"encoding/csv"
-	"io"
-	"os"
-
-	"github.com/ONSdigital/go-ns/log"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
-)
-
-// CreateSubject ...
-func (c *Common) CreateSubject(database, collection, fileName string, counter chan int) error {
-	m := c.Mongo
-
-	// Remove data from institution collection
-	if err := m.DropCollection(database, collection); err != nil {
-		os.Exit(1)
-	}
-
-	csvFile, err := os.Open(c.RelativeFileLocation + fileName + fileExtension)
-	if err != nil {
-		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
-	}
-	csvReader := csv.NewReader(bufio.NewReader(csvFile))
-
-	// Scan header row (not needed)
-	_, err = csvReader.Read()
-	if err != nil {
-		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
-	}
-
-	count := 0
-	for {
-		line, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
-		}
-
-		subject := &data.Subject{
-			KISCourseID: line[2],
-			KISMode:     line[3],
-			PublicUKPRN: line[0],
-			UKPRN:       line[1],
-		}
-
-		subjectObject, err := m.GetCAHCode(database, "cah-codes", line[4])
-		if err != nil {
-			log.ErrorC("failed to find cah code resource", err, log.Data{"line_count": count, "subject_resource": subject})
-		}
-
-		if subjectObject != nil {
-			subject.SubjectObject = subjectObject
-		}
-
-		if err := m.AddSubject(database, collection, subject); err != nil {
-			log.ErrorC("failed to add subject resource", err, log.Data{"line_count": count, "subject_resource": subject})
-			return err
-		}
+import "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+
+// subjectDataset loads the SBJ file into courses.subjects
+var subjectDataset = &Dataset{
+	Name:       "SBJ",
+	FileName:   "SBJ",
+	Database:   "courses",
+	Collection: "subjects",
+	Columns: []string{
+		"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE", "SBJ",
+	},
+	SubjectColumn: "SBJ",
+	Required:      courseKey,
+	Codes:         courseCodes,
+	Key:           statisticsKey,
+	Indexes:       courseIndexes,
+	XMLPath:       "INSTITUTION/KISCOURSE/SBJ",
+	Map:           mapSubject,
+}
 
-		count++
-		if count%1000 == 0 {
-			counter <- count
-			count = 0
-		}
+// mapSubject maps a row of the SBJ file to a subject resource
+func mapSubject(cols columns, line []string, subject *data.SubjectObject) (interface{}, error) {
+	sbj := &data.Subject{
+		KISCourseID:   cols.get(line, "KISCOURSEID"),
+		KISMode:       cols.get(line, "KISMODE"),
+		PublicUKPRN:   cols.get(line, "PUBUKPRN"),
+		UKPRN:         cols.get(line, "UKPRN"),
+		SubjectObject: subject,
 	}
 
-	counter <- count
-	log.Info("created subject resources", nil)
-
-	return nil
+	return sbj, nil
 }
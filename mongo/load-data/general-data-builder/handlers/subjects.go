@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
+)
+
+// Subjects is a read-only dictionary of subjects keyed by CAH code, shared by
+// every dataset with a subject column
+type Subjects map[string]*data.SubjectObject
+
+// LoadSubjects reads the CAH codes loaded into the store into a dictionary
+func (c *Common) LoadSubjects() (Subjects, error) {
+	var subjectObjects []*data.SubjectObject
+	if err := c.Store.Find(CAHCodes.Database, CAHCodes.Collection, nil, &subjectObjects); err != nil {
+		log.ErrorC("failed to find cah code resources", err, nil)
+		return nil, err
+	}
+
+	subjects := make(Subjects)
+	for _, subjectObject := range subjectObjects {
+		subjects[subjectObject.SubjectCode] = subjectObject
+	}
+
+	log.Info("loaded cah code dictionary", log.Data{"cah_codes": len(subjects)})
+
+	return subjects, nil
+}
+
+// ReadSubjects reads the CAH codes file into a dictionary without connecting
+// to mongo
+func (c *Common) ReadSubjects() (Subjects, error) {
+	subjects := make(Subjects)
+
+	err := c.readRows(CAHCodes, nil, func(_ int, cols columns, line []string) error {
+		document, err := CAHCodes.Map(cols, line, nil)
+		if err != nil {
+			return err
+		}
+
+		subject := document.(*data.SubjectObject)
+		subjects[subject.SubjectCode] = subject
+
+		return nil
+	}, func(rowErr *RowError) error {
+		return rowErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subjects, nil
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxProblems is the number of failing rows listed for each dataset in a
+// validation report, every failing row is still counted
+const maxProblems = 100
+
+// Codes which may appear in the coded columns of the HESA files
+var (
+	modeCodes    = []string{"1", "2", "3"}
+	countryCodes = []string{"XF", "XG", "XH", "XI"}
+)
+
+// courseKey are the columns identifying the course a row of statistics belongs to
+var courseKey = []string{"PUBUKPRN", "UKPRN", "KISCOURSEID", "KISMODE"}
+
+// courseCodes are the coded columns of every file keyed by course
+var courseCodes = map[string][]string{"KISMODE": modeCodes}
+
+// RowError reports a csv row which fails its checks or cannot be mapped to a
+// document
+type RowError struct {
+	LineNumber int      `json:"line_number"`
+	Column     string   `json:"column,omitempty"`
+	Value      string   `json:"value,omitempty"`
+	Reason     string   `json:"reason"`
+	Line       []string `json:"-"`
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %s", e.LineNumber, e.Reason)
+	}
+
+	return fmt.Sprintf("line %d: column %s with value %q: %s", e.LineNumber, e.Column, e.Value, e.Reason)
+}
+
+// newRowError returns the error for a row, keeping the column of errors raised
+// while mapping it
+func newRowError(err error, lineNumber int, line []string) *RowError {
+	rowErr, ok := err.(*RowError)
+	if !ok {
+		rowErr = &RowError{Reason: err.Error()}
+	}
+
+	rowErr.LineNumber = lineNumber
+	rowErr.Line = line
+
+	return rowErr
+}
+
+// atoi converts the value of the named column to an int
+func (c columns) atoi(line []string, name string) (int, error) {
+	value := c.get(line, name)
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &RowError{Column: name, Value: value, Reason: "not a whole number"}
+	}
+
+	return i, nil
+}
+
+// coordinate converts the value of the named column to a latitude or
+// longitude, which must lie between -limit and limit degrees
+func (c columns) coordinate(line []string, name string, limit float64) (float64, error) {
+	value := c.get(line, name)
+	if value == "" {
+		return 0, &RowError{Column: name, Reason: "required value is blank"}
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, &RowError{Column: name, Value: value, Reason: "not a number"}
+	}
+
+	if !(f >= -limit && f <= limit) {
+		return 0, &RowError{Column: name, Value: value, Reason: fmt.Sprintf("not between -%v and %v degrees", limit, limit)}
+	}
+
+	return f, nil
+}
+
+// number returns the whole number held in the named column, or nil if the
+// column is blank so that a published zero can be told apart from a value
+// which was not published
+func (c columns) number(line []string, name string) (*int, error) {
+	if c.get(line, name) == "" {
+		return nil, nil
+	}
+
+	i, err := c.atoi(line, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+// check returns an error for the first required column left blank or coded
+// column holding an unknown code
+func (d *Dataset) check(cols columns, line []string) error {
+	for _, name := range d.Required {
+		if cols.get(line, name) == "" {
+			return &RowError{Column: name, Reason: "required value is blank"}
+		}
+	}
+
+	names := make([]string, 0, len(d.Codes))
+	for name := range d.Codes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := cols.get(line, name)
+		if value != "" && !contains(d.Codes[name], value) {
+			return &RowError{Column: name, Value: value, Reason: "unknown code"}
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate runs every row of the dataset's csv file through its checks and
+// mapper without connecting to mongo, reporting each row which fails
+func (c *Common) Validate(dataset *Dataset) *Result {
+	result := &Result{Dataset: dataset.Name}
+
+	start := time.Now()
+	err := c.readRows(dataset, result, func(lineNumber int, cols columns, line []string) error {
+		result.RowsRead++
+
+		err := dataset.check(cols, line)
+		if err == nil {
+			_, err = dataset.Map(cols, line, c.subject(dataset, cols, line, result))
+		}
+
+		if err != nil {
+			result.addProblem(newRowError(err, lineNumber, line))
+		}
+
+		return nil
+	}, func(rowErr *RowError) error {
+		result.RowsRead++
+		result.addProblem(rowErr)
+		return nil
+	})
+	result.finishValidation(start, err)
+
+	return result
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/ONSdigital/go-ns/log"
+)
+
+// xmlElement is an element of the KIS XML file open while it is decoded
+type xmlElement struct {
+	name       string
+	path       string            // names of the element and its ancestors below the root, e.g. INSTITUTION/KISCOURSE/SALARY
+	lineNumber int               // line the element starts on
+	fields     map[string]string // text of each child element which holds no elements of its own
+	text       strings.Builder
+	complex    bool // element holds other elements
+	parent     *xmlElement
+}
+
+// get returns the value of the named field from the element or its nearest
+// ancestor holding it
+func (e *xmlElement) get(name string) string {
+	for ; e != nil; e = e.parent {
+		if value, ok := e.fields[name]; ok {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// readXMLRows streams the KIS XML file with a token decoder and calls fn with
+// a row for every element at the dataset's XML path. A row's columns are read
+// from the element's children, falling back to those of its ancestors for
+// keys such as PUBUKPRN and KISCOURSEID. Rows are held back until their
+// INSTITUTION element closes, as the children of an element are not in a set
+// order, so no more than one institution is in memory at a time.
+func (c *Common) readXMLRows(dataset *Dataset, fn func(lineNumber int, cols columns, line []string) error) error {
+	xmlFile, err := c.Source.Open(c.XMLFile)
+	if err != nil {
+		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"dataset": dataset.Name, "file name": c.XMLFile})
+		return err
+	}
+	defer xmlFile.Close()
+
+	decoder := xml.NewDecoder(bufio.NewReader(xmlFile))
+	cols := fixedColumns(dataset.Columns)
+
+	var (
+		top     *xmlElement
+		pending []*xmlElement
+	)
+	lineNumber := 1
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.ErrorC("encountered error reading xml", err, log.Data{"dataset": dataset.Name, "line_number": lineNumber})
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &xmlElement{name: t.Name.Local, lineNumber: lineNumber, fields: make(map[string]string), parent: top}
+			if top != nil {
+				top.complex = true
+				e.path = strings.TrimPrefix(top.path+"/"+e.name, "/")
+			}
+			top = e
+
+		case xml.CharData:
+			lineNumber += strings.Count(string(t), "\n")
+			if top != nil {
+				top.text.Write(t)
+			}
+
+		case xml.EndElement:
+			e := top
+			top = e.parent
+
+			if !e.complex && top != nil {
+				top.fields[e.name] = strings.TrimSpace(e.text.String())
+				e.fields[e.name] = top.fields[e.name]
+			}
+
+			if e.path == dataset.XMLPath {
+				pending = append(pending, e)
+			}
+
+			// The root element and each INSTITUTION hold every ancestor
+			// field of the rows found within them
+			if top == nil || top.parent == nil {
+				for _, row := range pending {
+					line := make([]string, len(dataset.Columns))
+					for i, column := range dataset.Columns {
+						line[i] = row.get(column)
+					}
+
+					if err = fn(row.lineNumber, cols, line); err != nil {
+						return err
+					}
+				}
+				pending = nil
+			}
+		}
+	}
+}
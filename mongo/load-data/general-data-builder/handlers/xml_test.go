@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+)
+
+func xmlCommon(t *testing.T) *Common {
+	src, err := source.Open("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Common{Source: src, XMLFile: "kis.xml"}
+}
+
+// readXMLLines returns every row of the dataset read from the KIS XML fixture
+func readXMLLines(t *testing.T, dataset *Dataset) (lineNumbers []int, lines [][]string) {
+	err := xmlCommon(t).readRows(dataset, nil, func(lineNumber int, cols columns, line []string) error {
+		lineNumbers = append(lineNumbers, lineNumber)
+		lines = append(lines, line)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to read %s from xml fixture: %v", dataset.Name, err)
+	}
+
+	return
+}
+
+func TestXMLMatchesCSV(t *testing.T) {
+	for _, dataset := range []*Dataset{nssDataset, tariffDataset} {
+		var documents []interface{}
+		_, lines := readXMLLines(t, dataset)
+		for _, line := range lines {
+			document, err := dataset.Map(fixedColumns(dataset.Columns), line, nil)
+			if err != nil {
+				t.Fatalf("failed to map %s row from xml fixture: %v", dataset.Name, err)
+			}
+			documents = append(documents, document)
+		}
+
+		if want := readFixture(t, dataset); !reflect.DeepEqual(documents, want) {
+			t.Errorf("%s documents read from xml do not match those read from csv\ngot  %+v\nwant %+v", dataset.Name, documents, want)
+		}
+	}
+}
+
+func TestXMLNestedRows(t *testing.T) {
+	lineNumbers, lines := readXMLLines(t, jobListDataset)
+
+	want := [][]string{
+		{"10007789", "10007789", "U1234", "1", "CAH02-04-01", "Nurses", "40", "1"},
+		{"10007789", "10007789", "U1234", "1", "CAH02-04-01", "Midwives", "20", "2"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got JOBLIST rows %q, want %q", lines, want)
+	}
+
+	if want := []int{7, 12}; !reflect.DeepEqual(lineNumbers, want) {
+		t.Errorf("got JOBLIST line numbers %v, want %v", lineNumbers, want)
+	}
+
+	_, lines = readXMLLines(t, subjectDataset)
+	want = [][]string{
+		{"10007789", "10007789", "U1234", "1", "CAH10-01-01"},
+		{"10007789", "10007789", "U1234", "1", "CAH02-04-01"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got SBJ rows %q, want %q", lines, want)
+	}
+}
@@ -3,352 +3,320 @@ package main
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/handlers"
-	"github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/mongo"
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
 )
 
 var (
 	mongoURI string
 
-	relativeFileLocation = "../files/"
-	cahCodes             = "CAHCODES"
-	commonData           = "COMMON"
-	continuation         = "CONTINUATION"
-	courseLocation       = "COURSELOCATION"
-	degreeClass          = "DEGREECLASS"
-	employment           = "EMPLOYMENT"
-	entry                = "ENTRY"
-	institution          = "INSTITUTION"
-	institutionLocation  = "LOCATION"
-	jobList              = "JOBLIST"
-	jobType              = "JOBTYPE"
-	leo                  = "LEO"
-	nhsNSS               = "NHSNSS"
-	nss                  = "NSS"
-	qualifications       = "kisaims"
-	salary               = "SALARY"
-	subject              = "SBJ"
-	tariff               = "TARIFF"
-	ucasCourse           = "UCASCOURSEID"
+	configFile       string
+	sourceLocation   = "../files/"
+	xmlFile          string
+	release          string
+	batchSize        = handlers.DefaultBatchSize
+	rejectsLocation  = "rejects/"
+	changesLocation  = "changes/"
+	storeDir         string
+	maxRejects       = handlers.DefaultMaxRejects
+	maxRejectPercent = handlers.DefaultMaxRejectPercent
+	rollback         bool
+	diff             bool
+	validateOnly     bool
+	datasetNames     string
+	skipNames        string
 )
 
 var (
 	wg sync.WaitGroup
 
-	cahCodesCh            = make(chan int)
-	courseLocationCh      = make(chan int)
-	qualificationsCh      = make(chan int)
-	commonDataCh          = make(chan int)
-	continuationCh        = make(chan int)
-	degreeClassCh         = make(chan int)
-	employmentCh          = make(chan int)
-	entryCh               = make(chan int)
-	institutionCh         = make(chan int)
-	institutionLocationCh = make(chan int)
-	jobListCh             = make(chan int)
-	jobTypeCh             = make(chan int)
-	leoCh                 = make(chan int)
-	nhsNSSCh              = make(chan int)
-	nssCh                 = make(chan int)
-	salaryCh              = make(chan int)
-	subjectCh             = make(chan int)
-	tariffCh              = make(chan int)
-	ucasCourseCh          = make(chan int)
+	counter = make(chan handlers.Progress)
 )
 
 func main() {
 	flag.StringVar(&mongoURI, "mongo-uri", mongoURI, "mongoDB URI")
-	flag.StringVar(&relativeFileLocation, "relative-file-location", relativeFileLocation, "relative location of files")
+	flag.StringVar(&storeDir, "store-dir", storeDir, "directory to write documents to as JSON lines files instead of mongo")
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the csv files, databases and collections, names not in it keep their defaults")
+	flag.StringVar(&sourceLocation, "source", sourceLocation, "directory or zip archive holding the csv files")
+	flag.StringVar(&sourceLocation, "relative-file-location", sourceLocation, "deprecated, use -source")
+	flag.StringVar(&xmlFile, "xml", xmlFile, "KIS XML file in the source to read datasets from instead of their csv files, e.g. kis.xml")
+	flag.StringVar(&release, "release", release, "HESA release the data belongs to, e.g. 2018, loading a release leaves others in place")
+	flag.IntVar(&batchSize, "batch-size", batchSize, "number of documents written to mongo in each bulk write")
+	flag.StringVar(&rejectsLocation, "rejects-location", rejectsLocation, "location the reject file of each dataset is written to")
+	flag.IntVar(&maxRejects, "max-rejects", maxRejects, "number of rows a dataset may reject before its load is abandoned, negative for no limit")
+	flag.Float64Var(&maxRejectPercent, "max-reject-percent", maxRejectPercent, "percentage of rows a dataset may reject before its load is abandoned, negative for no limit")
+	flag.BoolVar(&diff, "diff", diff, "write only the documents which differ from those stored for the release instead of reloading every row")
+	flag.StringVar(&changesLocation, "changes-location", changesLocation, "location the change report of each dataset is written to by -diff")
+	flag.BoolVar(&rollback, "rollback", rollback, "restore the previous generation of every collection instead of loading data")
+	flag.BoolVar(&validateOnly, "validate-only", validateOnly, "check every csv file and report problems without connecting to mongo")
+	flag.StringVar(&datasetNames, "datasets", datasetNames, "comma separated datasets to load, e.g. NSS,TARIFF, all datasets are loaded if not set")
+	flag.StringVar(&skipNames, "skip", skipNames, "comma separated datasets not to load, e.g. JOBLIST")
 	flag.Parse()
 
-	if mongoURI == "" {
-		log.Error(errors.New("missing mongo-uri flag"), nil)
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.ErrorC("failed to read config file", err, log.Data{"config": configFile})
 		os.Exit(1)
 	}
+	handlers.Configure(cfg)
 
-	mongodb := &mongo.Mongo{
-		URI: mongoURI,
+	datasets, err := handlers.Select(splitList(datasetNames), splitList(skipNames))
+	if err != nil {
+		log.ErrorC("invalid datasets or skip flag", err, log.Data{"datasets": datasetNames, "skip": skipNames})
+		os.Exit(1)
 	}
 
-	session, err := mongodb.Init()
+	src, err := source.Open(sourceLocation)
 	if err != nil {
-		log.ErrorC("failed to initialise mongo", err, log.Data{"mongo_db": mongodb})
+		log.ErrorC("failed to open source of csv files", err, log.Data{"source": sourceLocation})
 		os.Exit(1)
 	}
+	defer src.Close()
 
-	mongodb.Session = session
+	log.Info("reading csv files", src.LogData())
 
-	common := handlers.Common{
-		Mongo:                mongodb,
-		RelativeFileLocation: relativeFileLocation,
+	if validateOnly {
+		exit(src, nil, validate(handlers.Common{Source: src, XMLFile: xmlFile}, datasets), "validation")
 	}
 
-	go status()
-
-	err = common.CreateCahCodes("courses", "cah-codes", cahCodes, cahCodesCh)
-	if err != nil {
-		log.ErrorC("Unsuccessfully attempted to load cah code data", err, nil)
+	if mongoURI == "" && storeDir == "" {
+		log.Error(errors.New("missing mongo-uri or store-dir flag"), nil)
 		os.Exit(1)
 	}
 
-	wg.Add(18)
-
-	go func() (err error) { // first goroutine as it has the largest dataset
-		err = common.CreateJobList("statistics", "job-list", jobList, jobListCh)
-		wg.Done()
+	if batchSize < 1 {
+		log.Error(errors.New("batch-size flag must be at least 1"), log.Data{"batch_size": batchSize})
+		os.Exit(1)
+	}
 
-		return
-	}()
+	st, err := store.Open(mongoURI, storeDir)
+	if err != nil {
+		log.ErrorC("failed to open store", err, log.Data{"store_dir": storeDir})
+		os.Exit(1)
+	}
+	defer st.Close()
 
-	go func() (err error) {
-		err = common.CreateSubject("courses", "subjects", subject, subjectCh)
-		wg.Done()
+	if rollback {
+		if err = rollbackCollections(st, datasets); err != nil {
+			log.ErrorC("Unsuccessfully attempted to roll back ofs data", err, nil)
+			os.Exit(1)
+		}
 
+		log.Info("Successfully rolled back ofs data", nil)
 		return
-	}()
-
-	go func() (err error) {
-		err = common.CreateCourseLocation("courses", "locations", courseLocation, courseLocationCh)
-		wg.Done()
+	}
 
-		return
-	}()
+	for _, dataset := range datasets {
+		if !dataset.Reference && release == "" {
+			log.Error(errors.New("missing release flag"), log.Data{"dataset": dataset.Name})
+			os.Exit(1)
+		}
+	}
 
-	go func() (err error) {
-		err = common.CreateQualifications("courses", "qualifications", qualifications, qualificationsCh)
-		wg.Done()
+	common := handlers.Common{
+		Store:            st,
+		Source:           src,
+		XMLFile:          xmlFile,
+		Release:          release,
+		BatchSize:        batchSize,
+		RejectsLocation:  rejectsLocation,
+		MaxRejects:       maxRejects,
+		MaxRejectPercent: maxRejectPercent,
+		ChangesLocation:  changesLocation,
+	}
 
-		return
-	}()
+	load := common.Load
+	if diff {
+		load = common.Diff
+	}
 
-	go func() (err error) {
-		err = common.CreateUCASCourseID("courses", "ucas-course-ids", ucasCourse, ucasCourseCh)
-		wg.Done()
+	// Check every file against the columns its handler expects before any
+	// data is loaded
+	if err = checkHeaders(common, datasets); err != nil {
+		log.ErrorC("header rows do not match expected columns, no data has been loaded", err, nil)
+		os.Exit(1)
+	}
 
-		return
-	}()
+	go status()
 
-	go func() (err error) {
-		err = common.CreateInstitution("institutions", "raw", institution, institutionCh)
-		wg.Done()
+	var results []*handlers.Result
+	if datasets[0] == handlers.CAHCodes {
+		results = append(results, load(handlers.CAHCodes, counter))
+		datasets = datasets[1:]
 
-		return
-	}()
+		if results[0].Failed() {
+			exit(src, st, append(results, skipAll(datasets, "cah codes failed to load")...), "load")
+		}
+	}
 
-	go func() (err error) {
-		err = common.CreateInstitutionLocation("institutions", "locations", institutionLocation, institutionLocationCh)
-		wg.Done()
+	if handlers.NeedSubjects(datasets) {
+		if common.Subjects, err = common.LoadSubjects(); err != nil {
+			log.ErrorC("Unsuccessfully attempted to read cah code dictionary", err, nil)
+			exit(src, st, append(results, skipAll(datasets, "failed to read cah code dictionary")...), "load")
+		}
+	}
 
-		return
-	}()
+	loaded := make([]*handlers.Result, len(datasets))
+	for i, dataset := range datasets {
+		wg.Add(1)
 
-	go func() (err error) {
-		err = common.CreateCommonData("statistics", "common", commonData, commonDataCh)
-		wg.Done()
+		go func(i int, dataset *handlers.Dataset) {
+			defer wg.Done()
 
-		return
-	}()
+			loaded[i] = load(dataset, counter)
+		}(i, dataset)
+	}
 
-	go func() (err error) {
-		err = common.CreateContinuation("statistics", "continuation", continuation, continuationCh)
-		wg.Done()
+	wg.Wait()
 
-		return
-	}()
+	// Allow for last log of data load before exiting script
+	time.Sleep(1 * time.Second)
 
-	go func() (err error) {
-		err = common.CreateDegreeClass("statistics", "degree-class", degreeClass, degreeClassCh)
-		wg.Done()
+	exit(src, st, append(results, loaded...), "load")
+}
 
-		return
-	}()
+// validate checks the files of the datasets with the real mappers, looking
+// subjects up in the CAH codes file rather than in mongo
+func validate(common handlers.Common, datasets []*handlers.Dataset) []*handlers.Result {
+	var results []*handlers.Result
+	if datasets[0] == handlers.CAHCodes {
+		results = append(results, common.Validate(handlers.CAHCodes))
+		datasets = datasets[1:]
+	}
 
-	go func() (err error) {
-		err = common.CreateEmployment("statistics", "employment", employment, employmentCh)
-		wg.Done()
+	if handlers.NeedSubjects(datasets) {
+		subjects, err := common.ReadSubjects()
+		if err != nil {
+			log.ErrorC("unable to read cah codes file, subjects will not be checked", err, nil)
+		}
+		common.Subjects = subjects
+	}
 
-		return
-	}()
+	for _, dataset := range datasets {
+		results = append(results, common.Validate(dataset))
+	}
 
-	go func() (err error) {
-		err = common.CreateEntry("statistics", "entry", entry, entryCh)
-		wg.Done()
+	return results
+}
 
-		return
-	}()
+// splitList splits a comma separated flag value, ignoring blank entries
+func splitList(value string) (list []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
 
-	go func() (err error) {
-		err = common.CreateJobType("statistics", "job-type", jobType, jobTypeCh)
-		wg.Done()
+	return
+}
 
-		return
-	}()
+// skipAll returns a skipped result for every dataset
+func skipAll(datasets []*handlers.Dataset, reason string) (results []*handlers.Result) {
+	for _, dataset := range datasets {
+		results = append(results, handlers.Skipped(dataset, reason))
+	}
 
-	go func() (err error) {
-		err = common.CreateLongitudinalEducationOutcomes("statistics", "leo", leo, leoCh)
-		wg.Done()
+	return
+}
 
-		return
-	}()
+// exit prints the outcome of every dataset, closes the store, if one was
+// opened, and exits non-zero if any were not loaded or failed validation
+func exit(src *source.Source, st store.Store, results []*handlers.Result, action string) {
+	if err := handlers.WriteReport(os.Stdout, src, results); err != nil {
+		log.ErrorC("failed to write report", err, nil)
+	}
 
-	go func() (err error) {
-		err = common.CreateNHSNSS("statistics", "nhs-nss", nhsNSS, nhsNSSCh)
-		wg.Done()
+	if st != nil {
+		st.Close()
+	}
 
-		return
-	}()
+	var failed []string
+	for _, result := range results {
+		if !result.OK() {
+			failed = append(failed, result.Dataset)
+		}
+	}
 
-	go func() (err error) {
-		err = common.CreateNSS("statistics", "nss", nss, nssCh)
-		wg.Done()
+	if len(failed) > 0 {
+		logData := src.LogData()
+		logData["failed_datasets"] = failed
+		log.Error(fmt.Errorf("Unsuccessful %s of ofs data", action), logData)
+		src.Close()
+		os.Exit(1)
+	}
 
-		return
-	}()
+	log.Info(fmt.Sprintf("Successful %s of ofs data", action), src.LogData())
+	src.Close()
+	os.Exit(0)
+}
 
-	go func() (err error) {
-		err = common.CreateSalary("statistics", "salary", salary, salaryCh)
-		wg.Done()
+func checkHeaders(common handlers.Common, datasets []*handlers.Dataset) error {
+	var failed []string
+	for _, dataset := range datasets {
+		if err := common.CheckHeader(dataset); err != nil {
+			if headerErr, ok := err.(*handlers.HeaderError); ok {
+				log.ErrorC("header row does not match expected columns", err, headerErr.LogData())
+			}
+			failed = append(failed, dataset.FileName)
+		}
+	}
 
-		return
-	}()
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("unexpected header rows in files: %s", strings.Join(failed, ", "))
+	}
 
-	go func() (err error) {
-		err = common.CreateTariff("statistics", "tariff", tariff, tariffCh)
-		wg.Done()
+	return nil
+}
 
-		return
-	}()
+// rollbackCollections restores the previous generation of each dataset's collection
+func rollbackCollections(st store.Store, datasets []*handlers.Dataset) error {
+	var failed []string
+	for _, dataset := range datasets {
+		if err := st.Rollback(dataset.Database, dataset.Collection); err != nil {
+			log.ErrorC("failed to roll back collection", err, log.Data{"dataset": dataset.Name, "database": dataset.Database, "collection": dataset.Collection})
+			failed = append(failed, dataset.Name)
+			continue
+		}
 
-	wg.Wait()
+		log.Info("rolled back collection", log.Data{"dataset": dataset.Name, "database": dataset.Database, "collection": dataset.Collection})
+	}
 
-	// Allow for last log of data load before exiting script
-	time.Sleep(1 * time.Second)
-	if err != nil {
-		log.ErrorC("Unsuccessfully attempted to load ofs data", err, nil)
-		os.Exit(1)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to roll back datasets: %s", strings.Join(failed, ", "))
 	}
 
-	log.Info("Successfully loaded ofs data", nil)
+	return nil
 }
 
 func status() {
-	var (
-		totalCount = 0
-
-		cahCodeCount        = 0
-		courseLocationCount = 0
-		qualificationCount  = 0
-		subjectCount        = 0
-		ucasCourseIDCount   = 0
-
-		institutionCount         = 0
-		institutionLocationCount = 0
-
-		commonDataCount   = 0
-		continuationCount = 0
-		degreeClassCount  = 0
-		employmentCount   = 0
-		entryCount        = 0
-		jobListCount      = 0
-		jobTypeCount      = 0
-		leoCount          = 0
-		nhsNSSCount       = 0
-		nssCount          = 0
-		salaryCount       = 0
-		tariffCount       = 0
-	)
+	totalCount := 0
+	counts := make(map[string]int)
 
 	t := time.NewTicker(5 * time.Second)
 
 	for {
 		select {
-		case n := <-cahCodesCh:
-			cahCodeCount += n
-			totalCount += n
-		case n := <-courseLocationCh:
-			courseLocationCount += n
-			totalCount += n
-		case n := <-qualificationsCh:
-			qualificationCount += n
-			totalCount += n
-		case n := <-subjectCh:
-			subjectCount += n
-			totalCount += n
-		case n := <-ucasCourseCh:
-			ucasCourseIDCount += n
-			totalCount += n
-		case n := <-institutionCh:
-			institutionCount += n
-			totalCount += n
-		case n := <-institutionLocationCh:
-			institutionLocationCount += n
-			totalCount += n
-		case n := <-commonDataCh:
-			commonDataCount += n
-			totalCount += n
-		case n := <-continuationCh:
-			continuationCount += n
-			totalCount += n
-		case n := <-degreeClassCh:
-			degreeClassCount += n
-			totalCount += n
-		case n := <-employmentCh:
-			employmentCount += n
-			totalCount += n
-		case n := <-entryCh:
-			entryCount += n
-			totalCount += n
-		case n := <-jobListCh:
-			jobListCount += n
-			totalCount += n
-		case n := <-jobTypeCh:
-			jobTypeCount += n
-			totalCount += n
-		case n := <-leoCh:
-			leoCount += n
-			totalCount += n
-		case n := <-nhsNSSCh:
-			nhsNSSCount += n
-			totalCount += n
-		case n := <-nssCh:
-			nssCount += n
-			totalCount += n
-		case n := <-salaryCh:
-			salaryCount += n
-			totalCount += n
-		case n := <-tariffCh:
-			tariffCount += n
-			totalCount += n
+		case progress := <-counter:
+			counts[progress.Dataset] += progress.Count
+			totalCount += progress.Count
 		case <-t.C:
-			log.Info("Documents added",
-				log.Data{
-					"total":                 totalCount,
-					"cah_codes":             cahCodeCount,
-					"course_locations":      courseLocationCount,
-					"qualifications":        qualificationCount,
-					"subjects":              subjectCount,
-					"ucas_course_ids":       ucasCourseIDCount,
-					"institutions":          institutionCount,
-					"institution_locations": institutionLocationCount,
-					"common_datas":          commonDataCount,
-					"continuations":         continuationCount,
-					"degree_classes":        degreeClassCount,
-					"employments":           employmentCount,
-					"entries":               entryCount,
-					"job_lists":             jobListCount,
-					"job_types":             jobTypeCount,
-					"leos":                  leoCount,
-					"nhs_nsses":             nhsNSSCount,
-					"nsses":                 nssCount,
-					"salaries":              salaryCount,
-					"tariffs":               tariffCount,
-				},
-			)
+			logData := log.Data{"total": totalCount}
+			for dataset, count := range counts {
+				logData[dataset] = count
+			}
+
+			log.Info("Documents added", logData)
 		}
 	}
 }
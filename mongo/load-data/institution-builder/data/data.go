@@ -9,6 +9,7 @@ type Institution struct {
 	Name        string      `bson:"name"`
 	TEFOutcome  string      `bson:"tef_outcome"`
 	PublicUKPRN string      `bson:"public_ukprn"`
+	Release     string      `bson:"release"` // HESA release the institution was loaded from
 	UKPRN       string      `bson:"ukprn"`
 }
 
@@ -32,6 +33,19 @@ type Location struct {
 	Links     *LocationLinks `bson:"links"`
 	Longitude string         `bson:"longitude"`
 	Name      *Language      `bson:"name,omitempty"`
+	Point     *Point         `bson:"point,omitempty"`
+}
+
+// Point is a GeoJSON point, stored alongside the latitude and longitude a
+// location is published with so that locations can be found with $near
+type Point struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"` // longitude, latitude
+}
+
+// NewPoint returns the GeoJSON point at the latitude and longitude
+func NewPoint(latitude, longitude float64) *Point {
+	return &Point{Type: "Point", Coordinates: []float64{longitude, latitude}}
 }
 
 // LocationLinks represents a list of links related to location
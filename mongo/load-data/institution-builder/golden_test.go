@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
+	"github.com/ofs/alpha-scripts/mongo/load-data/internal/golden"
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// goldenAPIURL is the api url the institutions of the golden file link to
+const goldenAPIURL = "https://localhost:10000"
+
+// names are the institution names the fake unistats api knows, any other
+// institution is named from the ukprn lookup file
+var names = map[string]string{
+	"10007789": "University of Essex",
+}
+
+// TestGolden builds the institutions of the fixture ukprn lookup, INSTITUTION
+// and LOCATION files in testdata/golden, with the institution locations
+// general-data-builder loads seeded from testdata/store, and compares them
+// with testdata/golden/institutions.json. Run with -update to rewrite it after
+// a deliberate change to a mapping.
+func TestGolden(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"Name": names[strings.TrimPrefix(r.URL.Path, "/")]})
+	}))
+	defer api.Close()
+	institutionURL = api.URL + "/"
+
+	var err error
+	if src, err = source.Open(filepath.Join("testdata", "golden")); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	st = store.NewMemory()
+	golden.Seed(t, st, "institutions", "locations")
+
+	release = "2019"
+	configure(nil)
+	if links, err = (*config.Config)(nil).Links(goldenAPIURL); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = createInstitutions("token", authPassword, ukprnLookupFileName); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = updateInstitutions(institutionFileName); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = updateLocations(locationFileName); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = updateInstitutionLocations(); err != nil {
+		t.Fatal(err)
+	}
+
+	var institutions []map[string]interface{}
+	if err = st.Find(database, stagingCollection, nil, &institutions); err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Check(t, filepath.Join("testdata", "golden", "institutions.json"), institutions)
+}
+
+// TestRelink rewrites the links of the institutions of the golden file and
+// checks nothing else about them changes
+func TestRelink(t *testing.T) {
+	goldenFile, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "institutions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var institutions []map[string]interface{}
+	if err = json.Unmarshal(goldenFile, &institutions); err != nil {
+		t.Fatal(err)
+	}
+
+	st = store.NewMemory()
+	configure(nil)
+	for _, institution := range institutions {
+		if err = st.Insert(database, collection, institution); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if links, err = (*config.Config)(nil).Links("https://api.example.com/v1/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = relinkInstitutions(); err != nil {
+		t.Fatal(err)
+	}
+
+	var relinked []map[string]interface{}
+	if err = st.Find(database, collection, nil, &relinked); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.MarshalIndent(relinked, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Replace(string(goldenFile), goldenAPIURL, "https://api.example.com/v1", -1)
+	if string(got)+"\n" != expected {
+		t.Errorf("expected only the links of institutions to change, got:\n%s", got)
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// indexes of the institutions collection: an institution is unique by
+// public_ukprn within its release, the key every update of a load finds it by,
+// is also looked up by ukprn and is found near a point by its locations
+var indexes = []store.Index{
+	{Key: []string{"public_ukprn", "release"}, Unique: true},
+	{Key: []string{"ukprn", "release"}},
+	{Key: []string{"$2dsphere:locations.point"}},
+}
@@ -9,31 +9,50 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/ONSdigital/go-ns/log"
-	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	handlers "github.com/ofs/alpha-scripts/mongo/get-random-courses/handlers"
+	"github.com/ofs/alpha-scripts/mongo/load-data/config"
 	generalData "github.com/ofs/alpha-scripts/mongo/load-data/general-data-builder/data"
 	"github.com/ofs/alpha-scripts/mongo/load-data/institution-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+	"github.com/ofs/alpha-scripts/mongo/load-data/validation"
 )
 
 var (
 	authToken    string
 	authPassword = "anything"
 
-	mongoURI  string
-	mongoSize = 500
-
-	database             = "institutions"
-	collection           = "institutions"
-	relativeFileLocation = "../files/"
-	ukprnLookupFileName  = "UNISTATS_UKPRN_lookup_20160901"
-	institutionFileName  = "INSTITUTION"
-	locationFileName     = "LOCATION"
-	fileExtension        = ".csv"
+	mongoURI string
+	storeDir string
+	st       store.Store
+
+	configFile          string
+	database            = "institutions"
+	collection          = "institutions"
+	locationsCollection = "locations" // institution locations loaded by general-data-builder
+	sourceLocation      = "../files/"
+	rollback            bool
+	relink              bool
+	validateOnly        bool
+	release             string
+	ukprnLookupFileName = "UNISTATS_UKPRN_lookup_20160901"
+	institutionFileName = "INSTITUTION"
+	locationFileName    = "LOCATION"
+	fileExtension       = ".csv"
+
+	// Institutions are built in a staging collection, named after the
+	// configured collection, and only swapped into place once complete
+	stagingCollection string
+
+	src *source.Source
+
+	apiURL string
+	links  *config.Links
 
 	institutionURL = "https://data.unistats.ac.uk/api/v4/KIS/Institution/"
 )
@@ -42,26 +61,103 @@ func main() {
 	flag.StringVar(&authToken, "auth-token", authToken, "authentication token or username")
 	flag.StringVar(&authPassword, "auth-password", authPassword, "authentication password")
 	flag.StringVar(&mongoURI, "mongo-uri", mongoURI, "mongoDB URI")
-	flag.IntVar(&mongoSize, "mongo-size", mongoSize, "mongo size")
-	flag.StringVar(&relativeFileLocation, "relative-file-location", relativeFileLocation, "relative location of files")
+	flag.StringVar(&configFile, "config", configFile, "JSON file naming the csv files, databases and collections, names not in it keep their defaults")
+	flag.StringVar(&storeDir, "store-dir", storeDir, "directory to write documents to as JSON lines files instead of mongo")
+	flag.StringVar(&sourceLocation, "source", sourceLocation, "directory or zip archive holding the csv files")
+	flag.StringVar(&sourceLocation, "relative-file-location", sourceLocation, "deprecated, use -source")
+	flag.StringVar(&release, "release", release, "HESA release the data belongs to, e.g. 2018, loading a release leaves others in place")
+	flag.BoolVar(&rollback, "rollback", rollback, "restore the previous generation of institutions instead of loading data")
+	flag.BoolVar(&relink, "relink", relink, "rewrite the links of every stored institution from the api url and link templates instead of loading data")
+	flag.StringVar(&apiURL, "api-url", apiURL, "base url of the public api links are built from, overrides api_url in the config file")
+	flag.BoolVar(&validateOnly, "validate-only", validateOnly, "check every csv file and report problems without connecting to mongo")
 	flag.Parse()
 
-	if mongoURI == "" {
-		log.Error(errors.New("missing mongo-uri flag"), nil)
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.ErrorC("failed to read config file", err, log.Data{"config": configFile})
+		os.Exit(1)
+	}
+	configure(cfg)
+
+	if src, err = source.Open(sourceLocation); err != nil {
+		log.ErrorC("failed to open source of csv files", err, log.Data{"source": sourceLocation})
+		os.Exit(1)
+	}
+	defer src.Close()
+
+	log.Info("reading csv files", src.LogData())
+
+	if validateOnly {
+		results := validate()
+		if err := validation.WriteReport(os.Stdout, src, results); err != nil {
+			log.ErrorC("failed to write validation report", err, nil)
+		}
+
+		for _, result := range results {
+			if result.Status != validation.StatusValid {
+				log.Error(errors.New("Unsuccessful validation of institution data"), log.Data{"file": result.File, "status": result.Status})
+				os.Exit(1)
+			}
+		}
+
+		log.Info("Successful validation of institution data", src.LogData())
+		return
+	}
+
+	if mongoURI == "" && storeDir == "" {
+		log.Error(errors.New("missing mongo-uri or store-dir flag"), nil)
+		os.Exit(1)
+	}
+
+	if !rollback {
+		if links, err = cfg.Links(apiURL); err != nil {
+			log.ErrorC("failed to read api url and link templates", err, nil)
+			os.Exit(1)
+		}
+	}
+
+	if st, err = store.Open(mongoURI, storeDir); err != nil {
+		log.ErrorC("failed to open store", err, log.Data{"store_dir": storeDir})
 		os.Exit(1)
 	}
+	defer st.Close()
+
+	if rollback {
+		if err := st.Rollback(database, collection); err != nil {
+			log.ErrorC("Unsuccessfully attempted to roll back institution data", err, nil)
+			os.Exit(1)
+		}
+
+		log.Info("Successfully rolled back institution data", nil)
+		return
+	}
+
+	if relink {
+		if err := relinkInstitutions(); err != nil {
+			log.ErrorC("Unsuccessfully attempted to relink institution data", err, nil)
+			os.Exit(1)
+		}
+
+		log.Info("Successfully relinked institution data", nil)
+		return
+	}
 
 	if authToken == "" {
 		log.Error(errors.New("missing auth-token flag"), nil)
 		os.Exit(1)
 	}
 
-	// Remove data from institution collection
-	if err := dropCollection(); err != nil {
+	if release == "" {
+		log.Error(errors.New("missing release flag"), nil)
+		os.Exit(1)
+	}
+
+	if err := store.PrepareStaging(st, database, collection, release, indexes); err != nil {
 		os.Exit(1)
 	}
 
-	if err := createInstitutions(authToken, authPassword, ukprnLookupFileName); err != nil {
+	created, err := createInstitutions(authToken, authPassword, ukprnLookupFileName)
+	if err != nil {
 		os.Exit(1)
 	}
 
@@ -73,18 +169,38 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := updateInstitutionLocations(mongoSize); err != nil {
+	if err := updateInstitutionLocations(); err != nil {
 		os.Exit(1)
 	}
 
-	log.Info("Successfully loaded institution data", nil)
+	// Institution file may add institutions missing from the lookup file but
+	// should never leave fewer of the release than were created
+	count, err := st.Count(database, stagingCollection, store.Key{"release": release})
+	if err != nil {
+		log.ErrorC("failed to count institutions in staging collection", err, nil)
+		os.Exit(1)
+	}
+
+	if count == 0 || count < created {
+		log.Error(errors.New("staging collection failed validation, live collection has not been replaced"), log.Data{"staging_count": count, "created_count": created})
+		os.Exit(1)
+	}
+
+	if err := st.Swap(database, collection); err != nil {
+		log.ErrorC("failed to swap staging collection into place", err, nil)
+		os.Exit(1)
+	}
+
+	logData := src.LogData()
+	logData["release"] = release
+	log.Info("Successfully loaded institution data", logData)
 }
 
-func createInstitutions(authToken, authPassword, fileName string) error {
-	csvFile, err := os.Open(relativeFileLocation + fileName + fileExtension)
+func createInstitutions(authToken, authPassword, fileName string) (int, error) {
+	csvFile, err := src.Open(fileName + fileExtension)
 	if err != nil {
 		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": fileName})
-		return err
+		return 0, err
 	}
 	csvReader := csv.NewReader(bufio.NewReader(csvFile))
 
@@ -92,7 +208,7 @@ func createInstitutions(authToken, authPassword, fileName string) error {
 	_, err = csvReader.Read()
 	if err != nil {
 		log.ErrorC("encountered error immediately when processing header row", err, nil)
-		return err
+		return 0, err
 	}
 
 	var client *http.Client
@@ -115,7 +231,7 @@ func createInstitutions(authToken, authPassword, fileName string) error {
 		}
 		if err != nil {
 			log.ErrorC("encountered error reading csv", err, log.Data{"line_count": count, "csv_line": line})
-			return err
+			return count, err
 		}
 
 		// Should get the institution names from ukrlp API and not unistats API; will need to apply for a free account?
@@ -130,11 +246,12 @@ func createInstitutions(authToken, authPassword, fileName string) error {
 			Links:       &data.LinkList{},
 			Name:        institutionName,
 			PublicUKPRN: line[0],
+			Release:     release,
 		}
 
 		if err := addResource(institution); err != nil {
 			log.ErrorC("failed to add institution resource", err, log.Data{"line_count": count, "institution_resource": institution})
-			return err
+			return count, err
 		}
 
 		count++
@@ -142,83 +259,64 @@ func createInstitutions(authToken, authPassword, fileName string) error {
 
 	log.Info("Created institution resources", log.Data{"count": count})
 
-	return nil
+	return count, nil
 }
 
-func updateInstitutionLocations(size int) error {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return err
-	}
-	defer session.Close()
-
-	it := session.DB("institutions").C("locations").Find(bson.M{}).Batch(size).Iter()
+// configure names the files, database and collections from the config
+func configure(cfg *config.Config) {
+	ukprnLookupFileName = cfg.File("UKPRN-LOOKUP", ukprnLookupFileName)
+	institutionFileName = cfg.File("INSTITUTION", institutionFileName)
+	locationFileName = cfg.File("LOCATION", locationFileName)
 
-	for {
-		locations := make([]*generalData.InstitutionLocation, size)
-
-		itx := 0
-		for ; itx < len(locations); itx++ {
-			result := generalData.InstitutionLocation{}
+	collection = cfg.Collection(database, collection)
+	locationsCollection = cfg.Collection(database, locationsCollection)
+	database = cfg.Database(database)
 
-			if !it.Next(&result) {
-				break
-			}
-			locations[itx] = &result
-		}
-		if itx == 0 { // No results read from iterator. Nothing more to do.
-			time.Sleep(time.Second * 5)
-			break
-		}
+	stagingCollection = store.Staging(collection)
+}
 
-		// This will block if we've reached our concurrecy limit (sem buffer size)
-		if err := updateIstitutionLocations(&locations, itx); err != nil {
-			return err
-		}
+// updateInstitutionLocations adds the institution locations loaded by
+// general-data-builder to their institutions
+func updateInstitutionLocations() error {
+	var locations []*generalData.InstitutionLocation
+	if err := st.Find(database, locationsCollection, store.Key{"release": release}, &locations); err != nil {
+		log.ErrorC("failed to find institution locations", err, nil)
+		return err
 	}
 
-	return nil
-}
-
-func updateIstitutionLocations(locations *[]*generalData.InstitutionLocation, length int) (err error) {
-	i := 0
-	for i < length {
+	for _, institutionLocation := range locations {
 		location := &data.Location{
-			ID: (*locations)[i].LocationID,
+			ID: institutionLocation.LocationID,
 			Links: &data.LocationLinks{
 				Accommodation: &data.Language{
-					English: (*locations)[i].AccommodationURL,
-					Welsh:   (*locations)[i].AccommodationURLWelsh,
+					English: institutionLocation.AccommodationURL,
+					Welsh:   institutionLocation.AccommodationURLWelsh,
 				},
 				StudentUnion: &data.Language{
-					English: (*locations)[i].StudentUnionURL,
-					Welsh:   (*locations)[i].StudentUnionURLWelsh,
+					English: institutionLocation.StudentUnionURL,
+					Welsh:   institutionLocation.StudentUnionURLWelsh,
 				},
 			},
-			Latitude:  (*locations)[i].Latitude,
-			Longitude: (*locations)[i].Longitude,
+			Latitude:  institutionLocation.Latitude,
+			Longitude: institutionLocation.Longitude,
 			Name: &data.Language{
-				English: (*locations)[i].LocationName,
-				Welsh:   (*locations)[i].LocationNameWelsh,
+				English: institutionLocation.LocationName,
+				Welsh:   institutionLocation.LocationNameWelsh,
 			},
+			Point: storedPoint(institutionLocation.Point),
 		}
 
-		publicUKPRN := (*locations)[i].UKPRN
-
-		if err := insertLocation(publicUKPRN, location); err != nil {
+		if err := insertLocation(institutionLocation.UKPRN, location); err != nil {
 			log.ErrorC("failed to update institution resource with institution location data", err, log.Data{"location_resource": location})
 			return err
 		}
-
-		i++
 	}
 
 	return nil
 }
 
 func updateInstitutions(institutionFile string) error {
-	csvFile, err := os.Open(relativeFileLocation + institutionFile + fileExtension)
+	csvFile, err := src.Open(institutionFile + fileExtension)
 	if err != nil {
 		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": institutionFile})
 		return err
@@ -245,48 +343,16 @@ func updateInstitutions(institutionFile string) error {
 			return err
 		}
 
-		country, err := countryCodeToName(line[2])
+		institution, err := mapInstitution(line)
 		if err != nil {
 			log.Error(err, log.Data{"line_count": count, "csv_line": line})
 			return err
 		}
 
-		publicUKPRN := line[0]
-
-		institution := &data.Institution{
-			APROutcome: line[5],
-			Country: &data.Country{
-				Code: line[2],
-				Name: country,
-			},
-			Links: &data.LinkList{
-				Courses: "https://localhost:10000/institutions/" + line[1] + "/courses",
-				InstitutionStudentUnion: &data.Language{
-					English: line[6],
-					Welsh:   line[7],
-				},
-				Self: "https://localhost:10000/institutions/" + line[1],
-			},
-			TEFOutcome: line[4],
-			UKPRN:      line[1],
-		}
-
-		// Manually add institution name for ukprn 10008173
-		if publicUKPRN == "10008173" {
-			var locations []*data.Location
-			location := &data.Location{
-				Latitude:  "51.453256", // latitude and longitude taken from google
-				Longitude: "-0.963443",
-				Name: &data.Language{
-					English: institution.Name,
-				},
-			}
-			locations = append(locations, location)
+		institution.Links.Courses = links.Courses(institution.UKPRN)
+		institution.Links.Self = links.Institution(institution.UKPRN)
 
-			institution.Locations = locations
-			institution.Name = "University College of Estate Management"
-			institution.PublicUKPRN = publicUKPRN
-		}
+		publicUKPRN := line[0]
 
 		if err := upsertResource(publicUKPRN, institution); err != nil {
 			log.ErrorC("failed to update institution resource", err, log.Data{"line_count": count, "institution_resource": institution, "line": line})
@@ -302,7 +368,7 @@ func updateInstitutions(institutionFile string) error {
 }
 
 func updateLocations(locationFile string) error {
-	csvFile, err := os.Open(relativeFileLocation + locationFile + fileExtension)
+	csvFile, err := src.Open(locationFile + fileExtension)
 	if err != nil {
 		log.ErrorC("encountered error immediately when attempting to open file", err, log.Data{"file name": locationFile})
 		return err
@@ -329,31 +395,10 @@ func updateLocations(locationFile string) error {
 			return err
 		}
 
-		publicUKPRN := line[8]
-		ukprn := line[0]
-
-		location := &data.Location{
-			ID: line[3],
-			Links: &data.LocationLinks{
-				Accommodation: &data.Language{
-					English: line[1],
-					Welsh:   line[2],
-				},
-				StudentUnion: &data.Language{
-					English: line[10],
-					Welsh:   line[11],
-				},
-			},
-			Latitude:  line[6],
-			Longitude: line[7],
-			Name: &data.Language{
-				English: line[4],
-				Welsh:   line[5],
-			},
-		}
-
-		if publicUKPRN == "" {
-			publicUKPRN = ukprn
+		publicUKPRN, location, err := mapLocation(line)
+		if err != nil {
+			log.Error(err, log.Data{"line_count": count, "csv_line": line})
+			return err
 		}
 
 		if err := insertLocation(publicUKPRN, location); err != nil {
@@ -395,55 +440,168 @@ func countryCodeToName(code string) (name string, err error) {
 		err = fmt.Errorf("Unknown code: [%s]", code)
 	}
 
-	return name, nil
+	return
 }
 
-func addResource(institution *data.Institution) (err error) {
-	session, err := mgo.Dial(mongoURI)
+// mapInstitution maps a row of the INSTITUTION file to the institution fields
+// it updates
+func mapInstitution(line []string) (*data.Institution, error) {
+	country, err := countryCodeToName(line[2])
 	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
+		return nil, &validation.FieldError{Column: "COUNTRY", Value: line[2], Reason: err.Error()}
 	}
-	defer session.Close()
 
-	if err = session.DB(database).C(collection).Insert(institution); err != nil {
-		log.ErrorC("failed to create institution resource", err, nil)
-		return
+	publicUKPRN := line[0]
+
+	institution := &data.Institution{
+		APROutcome: line[5],
+		Country: &data.Country{
+			Code: line[2],
+			Name: country,
+		},
+		Links: &data.LinkList{
+			InstitutionStudentUnion: &data.Language{
+				English: line[6],
+				Welsh:   line[7],
+			},
+		},
+		TEFOutcome: line[4],
+		UKPRN:      line[1],
+	}
+
+	// Manually add institution name for ukprn 10008173
+	if publicUKPRN == "10008173" {
+		var locations []*data.Location
+		location := &data.Location{
+			Latitude:  "51.453256", // latitude and longitude taken from google
+			Longitude: "-0.963443",
+			Point:     data.NewPoint(51.453256, -0.963443),
+			Name: &data.Language{
+				English: institution.Name,
+			},
+		}
+		locations = append(locations, location)
+
+		institution.Locations = locations
+		institution.Name = "University College of Estate Management"
+		institution.PublicUKPRN = publicUKPRN
+	}
+
+	return institution, nil
+}
+
+// mapLocation maps a row of the LOCATION file to a location and the public
+// ukprn of the institution it belongs to
+func mapLocation(line []string) (publicUKPRN string, location *data.Location, err error) {
+	publicUKPRN = line[8]
+	ukprn := line[0]
+
+	location = &data.Location{
+		ID: line[3],
+		Links: &data.LocationLinks{
+			Accommodation: &data.Language{
+				English: line[1],
+				Welsh:   line[2],
+			},
+			StudentUnion: &data.Language{
+				English: line[10],
+				Welsh:   line[11],
+			},
+		},
+		Latitude:  line[6],
+		Longitude: line[7],
+		Name: &data.Language{
+			English: line[4],
+			Welsh:   line[5],
+		},
+	}
+
+	if publicUKPRN == "" {
+		publicUKPRN = ukprn
+	}
+
+	if location.Point, err = parsePoint(line[6], line[7]); err != nil {
+		return "", nil, err
 	}
 
 	return
 }
 
-func addName(publicUKPRN, name string) (err error) {
-	session, err := mgo.Dial(mongoURI)
+// storedPoint returns the GeoJSON point general-data-builder stored with an
+// institution location, or nil if it has none. A location read back without a
+// point may hold an empty one, as mgo fills the pointers of a document with
+// inline fields.
+func storedPoint(point *generalData.Point) *data.Point {
+	if point == nil || len(point.Coordinates) != 2 {
+		return nil
+	}
+
+	return (*data.Point)(point)
+}
+
+// parsePoint returns the GeoJSON point at a published latitude and longitude,
+// or nil if neither was published
+func parsePoint(latitude, longitude string) (*data.Point, error) {
+	if latitude == "" && longitude == "" {
+		return nil, nil
+	}
+
+	lat, err := parseCoordinate(latitude, "LATITUDE", 90)
 	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
+		return nil, err
 	}
-	defer session.Close()
 
-	if err = session.DB(database).C(collection).Update(bson.M{"public_ukprn": publicUKPRN, "name": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"name": name}}); err != nil {
-		if err != mgo.ErrNotFound {
-			log.ErrorC("failed to update institution resource with name", err, nil)
-		}
+	long, err := parseCoordinate(longitude, "LONGITUDE", 180)
+	if err != nil {
+		return nil, err
 	}
 
-	return
+	return data.NewPoint(lat, long), nil
 }
 
-func insertLocation(publicUKPRN string, location *data.Location) error {
-	session, err := mgo.Dial(mongoURI)
+// parseCoordinate converts the value of a latitude or longitude column, which
+// must lie between -limit and limit degrees
+func parseCoordinate(value, column string, limit float64) (float64, error) {
+	if value == "" {
+		return 0, &validation.FieldError{Column: column, Reason: "required value is blank"}
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
 	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return err
+		return 0, &validation.FieldError{Column: column, Value: value, Reason: "not a number"}
+	}
+
+	if !(f >= -limit && f <= limit) {
+		return 0, &validation.FieldError{Column: column, Value: value, Reason: fmt.Sprintf("not between -%v and %v degrees", limit, limit)}
+	}
+
+	return f, nil
+}
+
+func addResource(institution *data.Institution) (err error) {
+	if err = st.Insert(database, stagingCollection, institution); err != nil {
+		log.ErrorC("failed to create institution resource", err, nil)
+	}
+
+	return
+}
+
+// addName names an institution of the release which has no name
+func addName(publicUKPRN, name string) (err error) {
+	key := store.Key{"public_ukprn": publicUKPRN, "release": release, "name": nil}
+	if err = st.Update(database, stagingCollection, key, store.Update{Set: map[string]interface{}{"name": name}}); err != nil {
+		if err != store.ErrNotFound {
+			log.ErrorC("failed to update institution resource with name", err, nil)
+		}
 	}
-	defer session.Close()
 
-	selector := bson.M{"public_ukprn": publicUKPRN}
+	return
+}
 
-	query := createLocationUpdateQuery(location)
+func insertLocation(publicUKPRN string, location *data.Location) error {
+	key := store.Key{"public_ukprn": publicUKPRN, "release": release}
 
-	if err = session.DB(database).C(collection).Update(selector, query); err != nil {
+	if err := st.Update(database, stagingCollection, key, createLocationUpdate(location)); err != nil {
 		log.ErrorC("failed to insert location to institution resource", err, nil)
 	}
 
@@ -451,17 +609,10 @@ func insertLocation(publicUKPRN string, location *data.Location) error {
 }
 
 func upsertResource(publicUKPRN string, institution *data.Institution) error {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return err
-	}
-	defer session.Close()
-
-	selector := bson.M{"public_ukprn": publicUKPRN}
+	key := store.Key{"public_ukprn": publicUKPRN, "release": release}
 
-	update := createInstitutionUpdateQuery(institution)
-	if _, err = session.DB(database).C(collection).Upsert(selector, bson.M{"$set": update}); err != nil {
+	update := store.Update{Set: createInstitutionUpdateQuery(institution)}
+	if err := st.Upsert(database, stagingCollection, key, update); err != nil {
 		log.ErrorC("failed to upsert institution resource", err, nil)
 	}
 
@@ -528,7 +679,9 @@ func createInstitutionUpdateQuery(institution *data.Institution) bson.M {
 	return setUpdates
 }
 
-func createLocationUpdateQuery(location *data.Location) (query bson.M) {
+// createLocationUpdate adds a location to an institution's locations unless it
+// already holds an identical one
+func createLocationUpdate(location *data.Location) store.Update {
 	setUpdates := make(bson.M)
 
 	if location.ID != "" {
@@ -543,6 +696,10 @@ func createLocationUpdateQuery(location *data.Location) (query bson.M) {
 		setUpdates["longitude"] = location.Longitude
 	}
 
+	if location.Point != nil {
+		setUpdates["point"] = location.Point
+	}
+
 	if location.Name != nil {
 		setName := make(bson.M)
 		if location.Name.English != "" {
@@ -587,22 +744,5 @@ func createLocationUpdateQuery(location *data.Location) (query bson.M) {
 		setUpdates["links"] = setLinks
 	}
 
-	query = bson.M{"$addToSet": bson.M{"locations": setUpdates}}
-
-	return
-}
-
-func dropCollection() (err error) {
-	session, err := mgo.Dial(mongoURI)
-	if err != nil {
-		log.ErrorC("unable to create mongo session", err, nil)
-		return
-	}
-	defer session.Close()
-
-	if _, err = session.DB(database).C(collection).RemoveAll(nil); err != nil {
-		return
-	}
-
-	return
+	return store.Update{AddToSet: map[string]interface{}{"locations": setUpdates}}
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/ofs/alpha-scripts/mongo/load-data/institution-builder/data"
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+// relinkBatchSize is the number of institutions relinked in each bulk write
+const relinkBatchSize = 1000
+
+// relinkInstitutions rewrites the links of the stored institutions of every
+// release from the api url and link templates
+func relinkInstitutions() error {
+	var institutions []*data.Institution
+	if err := st.Find(database, collection, nil, &institutions); err != nil {
+		log.ErrorC("failed to read institutions", err, nil)
+		return err
+	}
+
+	var changes []store.Change
+	for _, institution := range institutions {
+		// Only institutions in the INSTITUTION file have a ukprn, and links
+		if institution.UKPRN == "" {
+			continue
+		}
+
+		if institution.Links == nil {
+			institution.Links = &data.LinkList{}
+		}
+		institution.Links.Courses = links.Courses(institution.UKPRN)
+		institution.Links.Self = links.Institution(institution.UKPRN)
+
+		// Institutions loaded before releases were tagged have no release,
+		// which a nil key value matches
+		var release interface{}
+		if institution.Release != "" {
+			release = institution.Release
+		}
+
+		changes = append(changes, store.Change{
+			Key:    store.Key{"public_ukprn": institution.PublicUKPRN, "release": release},
+			Update: &store.Update{Set: map[string]interface{}{"links": institution.Links}},
+		})
+	}
+
+	if err := store.Rewrite(st, database, collection, indexes, changes, relinkBatchSize); err != nil {
+		return err
+	}
+
+	log.Info("Relinked institutions", log.Data{"count": len(institutions)})
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/ofs/alpha-scripts/mongo/load-data/validation"
+)
+
+// validate checks every row of the files used to build institutions with the
+// same mappers as a load, without connecting to mongo or the unistats api
+func validate() []*validation.Result {
+	return []*validation.Result{
+		validation.File(src, ukprnLookupFileName, 2, func(line []string) error {
+			return validation.Required(line, map[int]string{0: "PUBUKPRN"})
+		}),
+		validation.File(src, institutionFileName, 8, func(line []string) error {
+			if err := validation.Required(line, map[int]string{0: "PUBUKPRN", 1: "UKPRN", 2: "COUNTRY"}); err != nil {
+				return err
+			}
+
+			_, err := mapInstitution(line)
+			return err
+		}),
+		validation.File(src, locationFileName, 12, func(line []string) error {
+			if err := validation.Required(line, map[int]string{0: "UKPRN", 3: "LOCID"}); err != nil {
+				return err
+			}
+
+			if line[9] != "" {
+				if _, err := countryCodeToName(line[9]); err != nil {
+					return &validation.FieldError{Column: "LOCCOUNTRY", Value: line[9], Reason: err.Error()}
+				}
+			}
+
+			_, _, err := mapLocation(line)
+			return err
+		}),
+	}
+}
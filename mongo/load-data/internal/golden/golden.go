@@ -0,0 +1,87 @@
+// Package golden compares the documents a builder's tests build with the
+// golden files kept in its testdata, and seeds the store with the documents
+// the other builders load.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/store"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the documents built")
+
+// Check compares documents, as indented JSON, with a golden file, reporting
+// the first line which differs. Run with -update to rewrite the golden file
+// instead, after a deliberate change to a mapping.
+func Check(t *testing.T, path string, documents interface{}) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(documents, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err = ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	gotLines, wantLines := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+
+		if g != w {
+			t.Errorf("documents differ from %s at line %d:\n got: %s\nwant: %s", path, i+1, g, w)
+			return
+		}
+	}
+}
+
+// Seed copies collections loaded by other builders from the JSON lines files
+// in testdata/store into the store
+func Seed(t *testing.T, st store.Store, db string, collections ...string) {
+	t.Helper()
+
+	files, err := store.NewFiles(filepath.Join("testdata", "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, collection := range collections {
+		var documents []map[string]interface{}
+		if err = files.Find(db, collection, nil, &documents); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, document := range documents {
+			if err = st.Insert(db, collection, document); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
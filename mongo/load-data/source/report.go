@@ -0,0 +1,36 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteReport writes a report of the files read from the source: a line
+// naming the source, the table written by table and a JSON summary of the
+// source and results
+func (s *Source) WriteReport(w io.Writer, table func(tw *tabwriter.Writer), results interface{}) error {
+	fmt.Fprintf(w, "SOURCE %s", s.Path)
+	if s.Archive != "" {
+		fmt.Fprintf(w, " (archive %s, sha256 %s)", s.Archive, s.Checksum)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	table(tw)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	summary, err := json.MarshalIndent(struct {
+		Source  *Source     `json:"source"`
+		Results interface{} `json:"results"`
+	}{s, results}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", summary)
+	return err
+}
@@ -0,0 +1,110 @@
+package source
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Source is a directory or zip archive holding the HESA csv files
+type Source struct {
+	Path     string `json:"path"`
+	Archive  string `json:"archive,omitempty"` // name of the zip archive, empty for a directory
+	Checksum string `json:"sha256,omitempty"`  // sha256 checksum of the zip archive
+
+	zip     *zip.ReadCloser
+	entries map[string]*zip.File
+}
+
+// Open opens a directory or zip archive of csv files
+func Open(location string) (*Source, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Source{Path: location}
+	if info.IsDir() {
+		return s, nil
+	}
+
+	if s.Checksum, err = checksum(location); err != nil {
+		return nil, err
+	}
+
+	if s.zip, err = zip.OpenReader(location); err != nil {
+		return nil, fmt.Errorf("%s is neither a directory nor a zip archive: %v", location, err)
+	}
+
+	// Entries are found by file name wherever they sit in the archive
+	s.Archive = filepath.Base(location)
+	s.entries = make(map[string]*zip.File)
+	for _, f := range s.zip.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := path.Base(f.Name)
+		if _, ok := s.entries[name]; ok {
+			s.zip.Close()
+			return nil, fmt.Errorf("archive %s holds more than one file named %s", s.Archive, name)
+		}
+		s.entries[name] = f
+	}
+
+	return s, nil
+}
+
+// Open opens the named file in the directory or archive
+func (s *Source) Open(name string) (io.ReadCloser, error) {
+	if s.zip == nil {
+		return os.Open(filepath.Join(s.Path, name))
+	}
+
+	f, ok := s.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("archive %s has no file named %s", s.Archive, name)
+	}
+
+	return f.Open()
+}
+
+// Close closes the archive, if any
+func (s *Source) Close() error {
+	if s.zip == nil {
+		return nil
+	}
+
+	return s.zip.Close()
+}
+
+// LogData identifies the files a run was loaded from, for use as log data
+func (s *Source) LogData() map[string]interface{} {
+	data := map[string]interface{}{"source": s.Path}
+	if s.Archive != "" {
+		data["archive"] = s.Archive
+		data["sha256"] = s.Checksum
+	}
+
+	return data
+}
+
+func checksum(location string) (string, error) {
+	f, err := os.Open(location)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package source
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := filepath.Join(dir, "files.zip")
+	f, err := os.Create(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := zip.NewWriter(f)
+	entry, err := w.Create("files/NSS.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = entry.Write([]byte("PUBUKPRN\n10000001\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	src, err := Open(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	if src.Archive != "files.zip" || len(src.Checksum) != 64 {
+		t.Errorf("expected archive name and sha256 checksum to be recorded, got %q %q", src.Archive, src.Checksum)
+	}
+
+	r, err := src.Open("NSS.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "PUBUKPRN\n10000001\n" {
+		t.Errorf("got %q from archive entry", b)
+	}
+
+	if _, err = src.Open("TARIFF.csv"); err == nil {
+		t.Error("expected an error opening a file missing from the archive")
+	}
+}
+
+func TestOpenDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "NSS.csv"), []byte("PUBUKPRN\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if src.Archive != "" || src.Checksum != "" {
+		t.Errorf("expected no archive for a directory, got %q %q", src.Archive, src.Checksum)
+	}
+
+	r, err := src.Open("NSS.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+}
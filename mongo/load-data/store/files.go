@@ -0,0 +1,328 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Files stores each collection as a file of JSON lines, one document per
+// line, at <dir>/<database>/<collection>.jsonl, so the builders can produce a
+// bundle of files for offline analysis. Collections are read into memory when
+// first used and changed there; the files of a collection's generations are
+// written when its staging collection is swapped into place or rolled back,
+// and those of any other changed collection when the store is closed.
+type Files struct {
+	dir string
+
+	mu     sync.Mutex
+	memory *Memory
+	loaded map[string]bool
+	dirty  map[string]collectionPath // collections changed since their files were written, by name
+}
+
+// collectionPath is the database and name of a collection
+type collectionPath struct {
+	database, collection string
+}
+
+// NewFiles returns the store of JSON lines files in dir, creating it if need be
+func NewFiles(dir string) (*Files, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Files{dir: dir, memory: NewMemory(), loaded: make(map[string]bool), dirty: make(map[string]collectionPath)}, nil
+}
+
+// path returns the location of a collection's file
+func (f *Files) path(database, collection string) string {
+	return filepath.Join(f.dir, database, collection+".jsonl")
+}
+
+// Insert adds a document to the collection
+func (f *Files) Insert(database, collection string, document interface{}) error {
+	return f.change(func() error {
+		return f.memory.Insert(database, collection, document)
+	}, database, collection)
+}
+
+// BulkInsert adds the documents to the collection
+func (f *Files) BulkInsert(database, collection string, documents []interface{}) (failed []InsertError, err error) {
+	err = f.change(func() (err error) {
+		failed, err = f.memory.BulkInsert(database, collection, documents)
+		return err
+	}, database, collection)
+
+	return failed, err
+}
+
+// FindOne decodes the first document matching the key into result
+func (f *Files) FindOne(database, collection string, key Key, result interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(database, collection); err != nil {
+		return err
+	}
+
+	return f.memory.FindOne(database, collection, key, result)
+}
+
+// Find decodes every document matching the key into results
+func (f *Files) Find(database, collection string, key Key, results interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(database, collection); err != nil {
+		return err
+	}
+
+	return f.memory.Find(database, collection, key, results)
+}
+
+// Count returns the number of documents matching the key
+func (f *Files) Count(database, collection string, key Key) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(database, collection); err != nil {
+		return 0, err
+	}
+
+	return f.memory.Count(database, collection, key)
+}
+
+// Update changes the first document matching the key
+func (f *Files) Update(database, collection string, key Key, update Update) error {
+	return f.change(func() error {
+		return f.memory.Update(database, collection, key, update)
+	}, database, collection)
+}
+
+// Upsert changes the first document matching the key, or adds one
+func (f *Files) Upsert(database, collection string, key Key, update Update) error {
+	return f.change(func() error {
+		return f.memory.Upsert(database, collection, key, update)
+	}, database, collection)
+}
+
+// Drop removes the collection, and its file once written
+func (f *Files) Drop(database, collection string) error {
+	return f.change(func() error {
+		return f.memory.Drop(database, collection)
+	}, database, collection)
+}
+
+// CopyOtherReleases copies the documents of every other release into staging
+func (f *Files) CopyOtherReleases(database, collection, release string) error {
+	return f.change(func() error {
+		return f.memory.CopyOtherReleases(database, collection, release)
+	}, database, collection, Staging(collection))
+}
+
+// CopyToStaging copies every document of the collection into staging
+func (f *Files) CopyToStaging(database, collection string) error {
+	return f.change(func() error {
+		return f.memory.CopyToStaging(database, collection)
+	}, database, collection, Staging(collection))
+}
+
+// ApplyChanges makes the changes to the collection
+func (f *Files) ApplyChanges(database, collection string, changes []Change) (matched int, failed []InsertError, err error) {
+	err = f.change(func() (err error) {
+		matched, failed, err = f.memory.ApplyChanges(database, collection, changes)
+		return err
+	}, database, collection)
+
+	return matched, failed, err
+}
+
+// Swap moves the staging collection into place and writes the files of the
+// collection's generations
+func (f *Files) Swap(database, collection string) error {
+	return f.flush(func() error {
+		return f.memory.Swap(database, collection)
+	}, database, collection, Staging(collection), Previous(collection))
+}
+
+// Rollback moves the previous generation back into place and writes the files
+// of the collection's generations
+func (f *Files) Rollback(database, collection string) error {
+	return f.flush(func() error {
+		return f.memory.Rollback(database, collection)
+	}, database, collection, Staging(collection), Previous(collection))
+}
+
+// EnsureIndexes does nothing, documents are found by scanning their collection
+func (f *Files) EnsureIndexes(database, collection string, indexes []Index) error {
+	return nil
+}
+
+// Close writes the file of every collection changed since it was last written
+func (f *Files) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for c, p := range f.dirty {
+		if err := f.write(p.database, p.collection); err != nil {
+			log.ErrorC("failed to write collection file", err, log.Data{"file": f.path(p.database, p.collection)})
+			continue
+		}
+
+		delete(f.dirty, c)
+	}
+}
+
+// change makes a change to collections in memory, marking them to be written
+func (f *Files) change(fn func() error, database string, collections ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, collection := range collections {
+		if err := f.load(database, collection); err != nil {
+			return err
+		}
+
+		f.dirty[name(database, collection)] = collectionPath{database, collection}
+	}
+
+	return fn()
+}
+
+// flush makes a change to collections in memory, then writes their files
+func (f *Files) flush(fn func() error, database string, collections ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, collection := range collections {
+		if err := f.load(database, collection); err != nil {
+			return err
+		}
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if err := f.write(database, collection); err != nil {
+			return err
+		}
+
+		delete(f.dirty, name(database, collection))
+	}
+
+	return nil
+}
+
+// load reads a collection's file into memory unless it has been read already
+func (f *Files) load(database, collection string) error {
+	c := name(database, collection)
+	if f.loaded[c] {
+		return nil
+	}
+
+	file, err := os.Open(f.path(database, collection))
+	if os.IsNotExist(err) {
+		f.loaded[c] = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	decoder.UseNumber()
+
+	var docs []bson.M
+	for {
+		var doc map[string]interface{}
+		if err = decoder.Decode(&doc); err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		docs = append(docs, fromJSON(doc).(bson.M))
+	}
+
+	f.memory.collections[c] = docs
+	f.loaded[c] = true
+	return nil
+}
+
+// write replaces a collection's file with the documents in memory, removing
+// it if the collection no longer exists
+func (f *Files) write(database, collection string) error {
+	path := f.path(database, collection)
+	docs, ok := f.memory.collections[name(database, collection)]
+	if !ok {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	for _, doc := range docs {
+		if err = encoder.Encode(doc); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// fromJSON returns a decoded JSON value as it would be stored by mongo, with
+// objects as documents and whole numbers as ints
+func fromJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		doc := make(bson.M, len(v))
+		for field, fieldValue := range v {
+			doc[field] = fromJSON(fieldValue)
+		}
+		return doc
+	case []interface{}:
+		for i := range v {
+			v[i] = fromJSON(v[i])
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i >= -1<<31 && i < 1<<31 {
+				return int(i)
+			}
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+
+	return value
+}
@@ -0,0 +1,526 @@
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// Memory holds documents in memory, for running the builders in tests without
+// a database. Documents are stored as they would be written to mongo, so a
+// document read back holds only what its bson encoding does.
+type Memory struct {
+	mu          sync.Mutex
+	collections map[string][]bson.M // keyed by database and collection name
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{collections: make(map[string][]bson.M)}
+}
+
+func name(database, collection string) string {
+	return database + "." + collection
+}
+
+// Insert adds a document to the collection
+func (m *Memory) Insert(database, collection string, document interface{}) error {
+	doc, err := normalise(document)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.add(name(database, collection), doc)
+	return nil
+}
+
+// BulkInsert adds the documents, any which cannot be encoded are returned as failed
+func (m *Memory) BulkInsert(database, collection string, documents []interface{}) (failed []InsertError, err error) {
+	docs := make([]bson.M, 0, len(documents))
+	for i, document := range documents {
+		doc, err := normalise(document)
+		if err != nil {
+			failed = append(failed, InsertError{Index: i, Err: err})
+			continue
+		}
+		docs = append(docs, doc)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.add(name(database, collection), docs...)
+	return failed, nil
+}
+
+// add appends documents to a collection, creating it if need be
+func (m *Memory) add(collection string, docs ...bson.M) {
+	m.collections[collection] = append(m.collections[collection], docs...)
+}
+
+// FindOne decodes the first document matching the key into result
+func (m *Memory) FindOne(database, collection string, key Key, result interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range m.collections[name(database, collection)] {
+		if matches(doc, key) {
+			return decode(doc, result)
+		}
+	}
+
+	return ErrNotFound
+}
+
+// Find decodes every document matching the key into results
+func (m *Memory) Find(database, collection string, key Key, results interface{}) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be a pointer to a slice, got %T", results)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := reflect.MakeSlice(slice.Type(), 0, 0)
+	for _, doc := range m.collections[name(database, collection)] {
+		if !matches(doc, key) {
+			continue
+		}
+
+		elem := reflect.New(elemType)
+		if elemType.Kind() == reflect.Ptr {
+			elem.Elem().Set(reflect.New(elemType.Elem()))
+			if err := decode(doc, elem.Elem().Interface()); err != nil {
+				return err
+			}
+		} else if err := decode(doc, elem.Interface()); err != nil {
+			return err
+		}
+		found = reflect.Append(found, elem.Elem())
+	}
+
+	slice.Set(found)
+	return nil
+}
+
+// Count returns the number of documents matching the key
+func (m *Memory) Count(database, collection string, key Key) (count int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range m.collections[name(database, collection)] {
+		if matches(doc, key) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Update changes the first document matching the key
+func (m *Memory) Update(database, collection string, key Key, update Update) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range m.collections[name(database, collection)] {
+		if matches(doc, key) {
+			return apply(doc, update)
+		}
+	}
+
+	return ErrNotFound
+}
+
+// Upsert changes the first document matching the key, or adds one
+func (m *Memory) Upsert(database, collection string, key Key, update Update) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := name(database, collection)
+	for _, doc := range m.collections[c] {
+		if matches(doc, key) {
+			return apply(doc, update)
+		}
+	}
+
+	doc := make(bson.M)
+	if err := apply(doc, Update{Set: key}); err != nil {
+		return err
+	}
+
+	if err := apply(doc, update); err != nil {
+		return err
+	}
+
+	m.add(c, doc)
+	return nil
+}
+
+// Drop removes the collection
+func (m *Memory) Drop(database, collection string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.collections, name(database, collection))
+	return nil
+}
+
+// CopyOtherReleases copies the documents of every other release into staging
+func (m *Memory) CopyOtherReleases(database, collection, release string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, ok := m.collections[name(database, collection)]
+	if !ok {
+		return nil
+	}
+
+	var others []bson.M
+	for _, doc := range docs {
+		if r, ok := doc["release"]; ok && r != release {
+			others = append(others, copyDocument(doc))
+		}
+	}
+
+	m.collections[name(database, Staging(collection))] = others
+	return nil
+}
+
+// CopyToStaging copies every document of the collection into staging
+func (m *Memory) CopyToStaging(database, collection string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, ok := m.collections[name(database, collection)]
+	if !ok {
+		return nil
+	}
+
+	copied := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		copied[i] = copyDocument(doc)
+	}
+
+	m.collections[name(database, Staging(collection))] = copied
+	return nil
+}
+
+// keyedChanges are the changes of a batch whose keys have the same fields, by
+// the values of those fields
+type keyedChanges struct {
+	fields  []string
+	changes map[string][]int // positions of the changes within the batch
+}
+
+// ApplyChanges makes the changes, finding the documents they are made to in a
+// single pass over the collection rather than one per change
+func (m *Memory) ApplyChanges(database, collection string, changes []Change) (matched int, failed []InsertError, err error) {
+	var added []bson.M
+	keyed := make(map[string]*keyedChanges) // by the fields of their keys
+	for i, change := range changes {
+		if change.Key == nil {
+			doc, err := normalise(change.Document)
+			if err != nil {
+				failed = append(failed, InsertError{Index: i, Err: err})
+				continue
+			}
+			added = append(added, doc)
+			continue
+		}
+
+		fields := make([]string, 0, len(change.Key))
+		for field := range change.Key {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		values := make([]interface{}, len(fields))
+		for j, field := range fields {
+			values[j] = change.Key[field]
+		}
+
+		normalised, err := normaliseValue(values)
+		if err != nil {
+			failed = append(failed, InsertError{Index: i, Err: err})
+			continue
+		}
+
+		signature := strings.Join(fields, ",")
+		k, ok := keyed[signature]
+		if !ok {
+			k = &keyedChanges{fields: fields, changes: make(map[string][]int)}
+			keyed[signature] = k
+		}
+
+		value := fmt.Sprintf("%#v", normalised)
+		k.changes[value] = append(k.changes[value], i)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := name(database, collection)
+	if docs, ok := m.collections[c]; ok && len(keyed) > 0 {
+		kept := docs[:0]
+		for _, doc := range docs {
+			removed := false
+			for _, k := range keyed {
+				value := fmt.Sprintf("%#v", keyValues(doc, k.fields))
+				positions, ok := k.changes[value]
+				if !ok {
+					continue
+				}
+
+				// Only the first document matching a key is changed
+				delete(k.changes, value)
+
+				for _, i := range positions {
+					if removed {
+						break
+					}
+
+					matched++
+					var err error
+					if removed, err = changeDocument(doc, changes[i]); err != nil {
+						failed = append(failed, InsertError{Index: i, Err: err})
+					}
+				}
+			}
+
+			if !removed {
+				kept = append(kept, doc)
+			}
+		}
+
+		m.collections[c] = kept
+	}
+
+	if len(added) > 0 {
+		m.add(c, added...)
+	}
+
+	return matched, failed, nil
+}
+
+// changeDocument makes a change to the stored document matching its key,
+// reporting whether the document is removed
+func changeDocument(doc bson.M, change Change) (bool, error) {
+	switch {
+	case change.Document != nil:
+		replacement, err := normalise(change.Document)
+		if err != nil {
+			return false, err
+		}
+
+		// As in mongo, the document keeps its id
+		id, hasID := doc["_id"]
+		for field := range doc {
+			delete(doc, field)
+		}
+		for field, value := range replacement {
+			doc[field] = value
+		}
+		if hasID {
+			doc["_id"] = id
+		}
+
+		return false, nil
+	case change.Update != nil:
+		return false, apply(doc, *change.Update)
+	}
+
+	return true, nil
+}
+
+// keyValues returns the value of each field, by dotted path, of a stored
+// document, nil for those it is missing
+func keyValues(doc bson.M, fields []string) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = lookup(doc, field)
+	}
+
+	return values
+}
+
+// Swap moves the staging collection into place
+func (m *Memory) Swap(database, collection string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live, staging := name(database, collection), name(database, Staging(collection))
+	if _, ok := m.collections[staging]; !ok {
+		return fmt.Errorf("no staging collection of %s to swap into place", live)
+	}
+
+	if docs, ok := m.collections[live]; ok {
+		m.collections[name(database, Previous(collection))] = docs
+	}
+
+	m.collections[live] = m.collections[staging]
+	delete(m.collections, staging)
+	return nil
+}
+
+// Rollback moves the previous generation back into place
+func (m *Memory) Rollback(database, collection string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live, previous := name(database, collection), name(database, Previous(collection))
+	docs, ok := m.collections[previous]
+	if !ok {
+		return fmt.Errorf("no previous generation of %s to roll back to", live)
+	}
+
+	if current, ok := m.collections[live]; ok {
+		m.collections[previous] = current
+	} else {
+		delete(m.collections, previous)
+	}
+
+	m.collections[live] = docs
+	return nil
+}
+
+// EnsureIndexes does nothing, documents are found by scanning their collection
+func (m *Memory) EnsureIndexes(database, collection string, indexes []Index) error {
+	return nil
+}
+
+// Close does nothing, the documents are kept until the store is dropped
+func (m *Memory) Close() {}
+
+// normalise returns a document as it would be stored by mongo
+func normalise(document interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// normaliseValue returns a value as it would be stored by mongo
+func normaliseValue(value interface{}) (interface{}, error) {
+	doc, err := normalise(bson.M{"v": value})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc["v"], nil
+}
+
+// decode decodes a stored document into result
+func decode(doc bson.M, result interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(raw, result)
+}
+
+// copyDocument returns a deep copy of a stored document
+func copyDocument(doc bson.M) bson.M {
+	copied, _ := normalise(doc)
+	return copied
+}
+
+// matches reports whether the document has the value of every field in the key
+func matches(doc bson.M, key Key) bool {
+	for path, value := range key {
+		expected, err := normaliseValue(value)
+		if err != nil {
+			return false
+		}
+
+		if !reflect.DeepEqual(lookup(doc, path), expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookup returns the value at a dotted path, nil if it is missing
+func lookup(doc bson.M, path string) interface{} {
+	var value interface{} = doc
+	for _, field := range strings.Split(path, ".") {
+		m, ok := value.(bson.M)
+		if !ok {
+			return nil
+		}
+		value = m[field]
+	}
+
+	return value
+}
+
+// apply makes an update to a stored document
+func apply(doc bson.M, update Update) error {
+	for path, value := range update.Set {
+		v, err := normaliseValue(value)
+		if err != nil {
+			return err
+		}
+
+		parent, field := parentOf(doc, path)
+		parent[field] = v
+	}
+
+	for path, value := range update.AddToSet {
+		v, err := normaliseValue(value)
+		if err != nil {
+			return err
+		}
+
+		parent, field := parentOf(doc, path)
+		array, _ := parent[field].([]interface{})
+
+		present := false
+		for _, element := range array {
+			if reflect.DeepEqual(element, v) {
+				present = true
+				break
+			}
+		}
+
+		if !present {
+			parent[field] = append(array, v)
+		}
+	}
+
+	return nil
+}
+
+// parentOf returns the document holding the field at a dotted path, creating
+// any missing documents along it
+func parentOf(doc bson.M, path string) (bson.M, string) {
+	fields := strings.Split(path, ".")
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := doc[field].(bson.M)
+		if !ok {
+			next = make(bson.M)
+			doc[field] = next
+		}
+		doc = next
+	}
+
+	return doc, fields[len(fields)-1]
+}
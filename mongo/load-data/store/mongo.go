@@ -0,0 +1,321 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ONSdigital/go-ns/log"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Mongo stores documents in mongo, it is the default store
+type Mongo struct {
+	session *mgo.Session
+}
+
+// Dial connects to mongo with strong consistency and a write mode of "majority"
+func Dial(uri string) (*Mongo, error) {
+	session, err := mgo.Dial(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	session.EnsureSafe(&mgo.Safe{WMode: "majority"})
+	session.SetMode(mgo.Strong, true)
+	return &Mongo{session: session}, nil
+}
+
+// Insert adds a document to the collection
+func (m *Mongo) Insert(database, collection string, document interface{}) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	return s.DB(database).C(collection).Insert(document)
+}
+
+// BulkInsert adds the documents in a single unordered bulk write
+func (m *Mongo) BulkInsert(database, collection string, documents []interface{}) (failed []InsertError, err error) {
+	s := m.session.Copy()
+	defer s.Close()
+
+	bulk := s.DB(database).C(collection).Bulk()
+	bulk.Unordered()
+	bulk.Insert(documents...)
+
+	_, failed, err = run(bulk)
+	return failed, err
+}
+
+// run runs a bulk write, returning the number of documents its updates and
+// removes matched and the writes within it which failed
+func run(bulk *mgo.Bulk) (matched int, failed []InsertError, err error) {
+	result, err := bulk.Run()
+	if err != nil {
+		bulkErr, ok := err.(*mgo.BulkError)
+		if !ok {
+			return 0, nil, err
+		}
+
+		for _, c := range bulkErr.Cases() {
+			failed = append(failed, InsertError{Index: c.Index, Err: c.Err})
+		}
+
+		return 0, failed, nil
+	}
+
+	return result.Matched, nil, nil
+}
+
+// FindOne decodes the first document matching the key into result
+func (m *Mongo) FindOne(database, collection string, key Key, result interface{}) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	if err := s.DB(database).C(collection).Find(bson.M(key)).One(result); err != nil {
+		if err == mgo.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find decodes every document matching the key into results
+func (m *Mongo) Find(database, collection string, key Key, results interface{}) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	return s.DB(database).C(collection).Find(bson.M(key)).All(results)
+}
+
+// Count returns the number of documents matching the key
+func (m *Mongo) Count(database, collection string, key Key) (int, error) {
+	s := m.session.Copy()
+	defer s.Close()
+
+	return s.DB(database).C(collection).Find(bson.M(key)).Count()
+}
+
+// Update changes the first document matching the key
+func (m *Mongo) Update(database, collection string, key Key, update Update) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	if err := s.DB(database).C(collection).Update(bson.M(key), updateDocument(update)); err != nil {
+		if err == mgo.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Upsert changes the first document matching the key, or adds one
+func (m *Mongo) Upsert(database, collection string, key Key, update Update) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	_, err := s.DB(database).C(collection).Upsert(bson.M(key), updateDocument(update))
+	return err
+}
+
+// updateDocument returns the mongo update operators of an update, leaving out
+// any with no fields as mongo rejects them
+func updateDocument(update Update) bson.M {
+	document := make(bson.M)
+	if len(update.Set) > 0 {
+		document["$set"] = update.Set
+	}
+
+	if len(update.AddToSet) > 0 {
+		document["$addToSet"] = update.AddToSet
+	}
+
+	return document
+}
+
+// Drop removes the collection
+func (m *Mongo) Drop(database, collection string) (err error) {
+	s := m.session.Copy()
+	defer s.Close()
+
+	if err = s.DB(database).C(collection).DropCollection(); err != nil && err.Error() == "ns not found" {
+		err = nil
+	}
+
+	return
+}
+
+// CopyOtherReleases copies the documents of every other release into staging
+func (m *Mongo) CopyOtherReleases(database, collection, release string) error {
+	return m.copyToStaging(database, collection, bson.M{"release": bson.M{"$exists": true, "$ne": release}})
+}
+
+// CopyToStaging copies every document of the collection into staging
+func (m *Mongo) CopyToStaging(database, collection string) error {
+	return m.copyToStaging(database, collection, bson.M{})
+}
+
+// copyToStaging replaces the staging collection with the documents of the
+// collection matching the query, leaving it alone if the collection does not exist
+func (m *Mongo) copyToStaging(database, collection string, match bson.M) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	exists, err := collectionExists(s, database, collection)
+	if err != nil || !exists {
+		return err
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$out": Staging(collection)},
+	}
+
+	if err = s.DB(database).C(collection).Pipe(pipeline).Iter().Close(); err != nil {
+		log.ErrorC("failed to copy documents into staging collection", err, log.Data{"database": database, "collection": collection, "match": match})
+		return err
+	}
+
+	return nil
+}
+
+// ApplyChanges makes the changes in a single unordered bulk write
+func (m *Mongo) ApplyChanges(database, collection string, changes []Change) (matched int, failed []InsertError, err error) {
+	s := m.session.Copy()
+	defer s.Close()
+
+	bulk := s.DB(database).C(collection).Bulk()
+	bulk.Unordered()
+	for _, change := range changes {
+		switch {
+		case change.Key == nil:
+			bulk.Insert(change.Document)
+		case change.Document != nil:
+			bulk.Update(bson.M(change.Key), change.Document)
+		case change.Update != nil:
+			bulk.Update(bson.M(change.Key), updateDocument(*change.Update))
+		default:
+			bulk.Remove(bson.M(change.Key))
+		}
+	}
+
+	return run(bulk)
+}
+
+// Swap moves the staging collection into place
+func (m *Mongo) Swap(database, collection string) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	exists, err := collectionExists(s, database, collection)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if err = renameCollection(s, database, collection, Previous(collection)); err != nil {
+			return err
+		}
+	}
+
+	return renameCollection(s, database, Staging(collection), collection)
+}
+
+// Rollback moves the previous generation back into place
+func (m *Mongo) Rollback(database, collection string) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	previous := Previous(collection)
+	exists, err := collectionExists(s, database, previous)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("no previous generation of %s.%s to roll back to", database, collection)
+	}
+
+	if exists, err = collectionExists(s, database, collection); err != nil {
+		return err
+	}
+
+	if !exists {
+		return renameCollection(s, database, previous, collection)
+	}
+
+	// Park the live collection in staging while the previous generation moves into place
+	staging := Staging(collection)
+	if err = renameCollection(s, database, collection, staging); err != nil {
+		return err
+	}
+
+	if err = renameCollection(s, database, previous, collection); err != nil {
+		return err
+	}
+
+	return renameCollection(s, database, staging, previous)
+}
+
+// EnsureIndexes builds each index on the collection unless it already exists,
+// logging the time taken to build each
+func (m *Mongo) EnsureIndexes(database, collection string, indexes []Index) error {
+	s := m.session.Copy()
+	defer s.Close()
+
+	start := time.Now()
+	for _, index := range indexes {
+		indexStart := time.Now()
+		if err := s.DB(database).C(collection).EnsureIndex(mgo.Index{Key: index.Key, Unique: index.Unique}); err != nil {
+			log.ErrorC("failed to ensure index", err, log.Data{"database": database, "collection": collection, "key": index.Key, "unique": index.Unique})
+			return err
+		}
+
+		log.Info("ensured index", log.Data{"database": database, "collection": collection, "key": index.Key, "unique": index.Unique, "duration": time.Since(indexStart).Round(time.Millisecond).String()})
+	}
+
+	log.Info("ensured indexes", log.Data{"database": database, "collection": collection, "indexes": len(indexes), "duration": time.Since(start).Round(time.Millisecond).String()})
+	return nil
+}
+
+// Close closes the session
+func (m *Mongo) Close() {
+	m.session.Close()
+}
+
+func collectionExists(s *mgo.Session, database, collection string) (bool, error) {
+	names, err := s.DB(database).CollectionNames()
+	if err != nil {
+		log.ErrorC("failed to list collections", err, log.Data{"database": database})
+		return false, err
+	}
+
+	for _, name := range names {
+		if name == collection {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// renameCollection renames a collection within a database, replacing any
+// collection already using the new name
+func renameCollection(s *mgo.Session, database, from, to string) error {
+	command := bson.D{
+		{Name: "renameCollection", Value: database + "." + from},
+		{Name: "to", Value: database + "." + to},
+		{Name: "dropTarget", Value: true},
+	}
+
+	if err := s.Run(command, nil); err != nil {
+		log.ErrorC("failed to rename collection", err, log.Data{"database": database, "from": from, "to": to})
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/ONSdigital/go-ns/log"
+)
+
+// Rewrite changes the stored documents of the collection without reloading
+// them, as a builder's -relink does. The collection is copied into its emptied
+// and indexed staging collection, the changes are made to the copy in bulk
+// writes of batchSize and it is swapped into place once it holds as many
+// documents as the collection and every change has matched a document, so a
+// rewrite can be rolled back like a load.
+func Rewrite(st Store, database, collection string, indexes []Index, changes []Change, batchSize int) error {
+	logData := log.Data{"database": database, "collection": collection}
+
+	if err := st.Drop(database, Staging(collection)); err != nil {
+		log.ErrorC("failed to drop staging collection", err, logData)
+		return err
+	}
+
+	if err := st.EnsureIndexes(database, Staging(collection), indexes); err != nil {
+		return err
+	}
+
+	count, err := st.Count(database, collection, nil)
+	if err != nil {
+		log.ErrorC("failed to count documents in collection", err, logData)
+		return err
+	}
+
+	if err = st.CopyToStaging(database, collection); err != nil {
+		log.ErrorC("failed to copy documents into staging collection", err, logData)
+		return err
+	}
+
+	var keyed, matched int
+	for _, change := range changes {
+		if change.Key != nil {
+			keyed++
+		}
+	}
+
+	for start := 0; start < len(changes); start += batchSize {
+		end := start + batchSize
+		if end > len(changes) {
+			end = len(changes)
+		}
+
+		batchMatched, failed, err := st.ApplyChanges(database, Staging(collection), changes[start:end])
+		if err != nil {
+			log.ErrorC("failed to apply batch of changes", err, log.Data{"database": database, "collection": collection, "changes": end - start})
+			return err
+		}
+
+		if len(failed) > 0 {
+			for _, changeErr := range failed {
+				log.ErrorC("failed to apply change", changeErr.Err, log.Data{"database": database, "collection": collection, "key": changes[start+changeErr.Index].Key})
+			}
+			return errors.New("failed to apply changes, live collection has not been replaced")
+		}
+
+		matched += batchMatched
+	}
+
+	if matched != keyed {
+		err = errors.New("changes did not all match a document, live collection has not been replaced")
+		log.Error(err, log.Data{"database": database, "collection": collection, "keyed_changes": keyed, "matched": matched})
+		return err
+	}
+
+	stagingCount, err := st.Count(database, Staging(collection), nil)
+	if err != nil {
+		log.ErrorC("failed to count documents in staging collection", err, logData)
+		return err
+	}
+
+	if stagingCount != count {
+		err = errors.New("staging collection failed validation, live collection has not been replaced")
+		log.Error(err, log.Data{"database": database, "collection": collection, "staging_count": stagingCount, "count": count})
+		return err
+	}
+
+	if err = st.Swap(database, collection); err != nil {
+		log.ErrorC("failed to swap staging collection into place", err, logData)
+		return err
+	}
+
+	return nil
+}
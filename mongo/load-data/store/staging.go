@@ -0,0 +1,25 @@
+package store
+
+import (
+	"github.com/ONSdigital/go-ns/log"
+)
+
+// PrepareStaging readies the staging collection of the collection for a
+// release to be loaded into: anything left by a previous load is removed, the
+// documents of every other release are copied into it and its indexes are
+// built. Documents loaded before releases were recorded are not copied.
+func PrepareStaging(st Store, database, collection, release string, indexes []Index) error {
+	logData := log.Data{"database": database, "collection": collection, "release": release}
+
+	if err := st.Drop(database, Staging(collection)); err != nil {
+		log.ErrorC("failed to drop staging collection", err, logData)
+		return err
+	}
+
+	if err := st.CopyOtherReleases(database, collection, release); err != nil {
+		log.ErrorC("failed to copy other releases into staging collection", err, logData)
+		return err
+	}
+
+	return st.EnsureIndexes(database, Staging(collection), indexes)
+}
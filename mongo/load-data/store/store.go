@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+)
+
+// ErrNotFound is returned when no stored document matches a key
+var ErrNotFound = errors.New("not found")
+
+// Store holds the documents the builders load, in collections named by
+// database and collection. Each builder loads into the staging collection of
+// a collection and swaps it into place once complete, keeping the generation
+// it replaces as the previous one for rollback.
+type Store interface {
+	// Insert adds a document to the collection
+	Insert(database, collection string, document interface{}) error
+	// BulkInsert adds the documents to the collection, a document that fails
+	// does not stop the rest being added and is returned as failed
+	BulkInsert(database, collection string, documents []interface{}) ([]InsertError, error)
+	// FindOne decodes the first document matching the key into result,
+	// returning ErrNotFound if none match
+	FindOne(database, collection string, key Key, result interface{}) error
+	// Find decodes every document matching the key into results, a pointer
+	// to a slice
+	Find(database, collection string, key Key, results interface{}) error
+	// Count returns the number of documents matching the key
+	Count(database, collection string, key Key) (int, error)
+	// Update changes the first document matching the key, returning
+	// ErrNotFound if none match
+	Update(database, collection string, key Key, update Update) error
+	// Upsert changes the first document matching the key, or adds one made
+	// of the key and update if none match
+	Upsert(database, collection string, key Key, update Update) error
+	// Drop removes the collection, it is not an error for it not to exist
+	Drop(database, collection string) error
+	// CopyOtherReleases copies the documents of every release but the one
+	// being loaded from the collection into its empty staging collection.
+	// Documents loaded before releases were recorded are not copied.
+	CopyOtherReleases(database, collection, release string) error
+	// CopyToStaging copies every document of the collection into its empty
+	// staging collection, for changes to be made to before it is swapped into
+	// place
+	CopyToStaging(database, collection string) error
+	// ApplyChanges makes the changes to the collection in a single bulk write,
+	// a change that fails does not stop the rest being made and is returned as
+	// failed. It returns how many of the changes with a key matched a document.
+	ApplyChanges(database, collection string, changes []Change) (matched int, failed []InsertError, err error)
+	// Swap moves the staging collection into place, keeping the collection it
+	// replaces as the previous generation
+	Swap(database, collection string) error
+	// Rollback moves the previous generation back into place; the generation
+	// it replaces becomes the previous one so the rollback can be undone
+	Rollback(database, collection string) error
+	// EnsureIndexes builds each index on the collection unless it already
+	// exists. Only mongo builds indexes, the other stores have no use for them.
+	// Indexes are built on a staging collection before anything is written to
+	// it, so a duplicate is rejected as it is written, and swapping it into
+	// place keeps them.
+	EnsureIndexes(database, collection string, indexes []Index) error
+	Close()
+}
+
+// Key is the value of each field, by dotted path, a document must have to
+// match. A nil value matches a field which is missing or null and an empty
+// key matches every document.
+type Key map[string]interface{}
+
+// Update sets fields of a document and adds values to its array fields, by
+// dotted path
+type Update struct {
+	Set      map[string]interface{}
+	AddToSet map[string]interface{} // each value is added unless the array already holds an equal one
+}
+
+// Change is a write made by ApplyChanges. A change with no key adds its
+// document, otherwise it is made to the first document matching the key: the
+// document replaces it, the update changes it, or it is removed if the change
+// has neither.
+type Change struct {
+	Key      Key
+	Document interface{}
+	Update   *Update
+}
+
+// Index is an index built on a collection. As with mgo, a field of the key
+// may be prefixed with "-" to sort it in descending order or "$2dsphere:" to
+// index GeoJSON points.
+type Index struct {
+	Key    []string
+	Unique bool
+}
+
+// InsertError reports a document or change within a bulk write that could not
+// be written
+type InsertError struct {
+	Index int // position of the document or change within the batch, or -1 if unknown
+	Err   error
+}
+
+// Staging returns the name of the collection data is loaded into before it is
+// swapped into place
+func Staging(collection string) string {
+	return collection + "-staging"
+}
+
+// Previous returns the name of the collection the replaced generation of data
+// is kept in so it can be rolled back
+func Previous(collection string) string {
+	return collection + "-previous"
+}
+
+// Open returns the JSON lines directory store at dir if one is given, or the
+// mongo store at uri otherwise
+func Open(uri, dir string) (Store, error) {
+	if dir != "" {
+		return NewFiles(dir)
+	}
+
+	if uri == "" {
+		return nil, errors.New("missing mongo uri or store directory")
+	}
+
+	return Dial(uri)
+}
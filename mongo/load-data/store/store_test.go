@@ -0,0 +1,255 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type location struct {
+	ID   string `bson:"id"`
+	Name string `bson:"name,omitempty"`
+}
+
+type institution struct {
+	Name        string      `bson:"name"`
+	PublicUKPRN string      `bson:"public_ukprn"`
+	Release     string      `bson:"release"`
+	Locations   []*location `bson:"locations"`
+	Students    *int        `bson:"students,omitempty"`
+	Country     *country    `bson:"country,omitempty"`
+}
+
+type country struct {
+	Code string `bson:"code"`
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+	testChanges(t, NewMemory())
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+	testChanges(t, s)
+
+	// Changes to a collection which has not been swapped into place are
+	// written when the store is closed
+	if _, err = os.Stat(filepath.Join(dir, "changes", Staging("institutions")+".jsonl")); !os.IsNotExist(err) {
+		t.Errorf("expected the changed staging collection not to be written before the store is closed, got %v", err)
+	}
+	s.Close()
+
+	// A new store over the same directory reads back what was written
+	reopened, err := NewFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var live []institution
+	if err = reopened.Find("institutions", "institutions", Key{"public_ukprn": "10000001", "release": "2019"}, &live); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(live) != 1 || live[0].Students == nil || *live[0].Students != 1200 || len(live[0].Locations) != 1 {
+		t.Errorf("expected the 2019 institution to be read back from its file, got %+v", live)
+	}
+
+	if count, _ := reopened.Count("changes", Staging("institutions"), nil); count != 3 {
+		t.Errorf("expected the 3 changed institutions to be read back from their file, got %d", count)
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	students := 1200
+	old := &institution{Name: "Old", PublicUKPRN: "10000001", Release: "2018"}
+	if err := s.Insert("institutions", Staging("institutions"), old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Swap("institutions", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Loading 2019 keeps 2018 in place
+	if err := s.Drop("institutions", Staging("institutions")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CopyOtherReleases("institutions", "institutions", "2019"); err != nil {
+		t.Fatal(err)
+	}
+
+	failed, err := s.BulkInsert("institutions", Staging("institutions"), []interface{}{
+		&institution{Name: "New", PublicUKPRN: "10000001", Release: "2019", Students: &students},
+	})
+	if err != nil || len(failed) > 0 {
+		t.Fatalf("unexpected bulk insert failure %v %v", failed, err)
+	}
+
+	key := Key{"public_ukprn": "10000001", "release": "2019"}
+	add := Update{AddToSet: map[string]interface{}{"locations": &location{ID: "A"}}}
+	for i := 0; i < 2; i++ {
+		if err = s.Update("institutions", Staging("institutions"), key, add); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = s.Update("institutions", Staging("institutions"), Key{"public_ukprn": "10000002"}, add); err != ErrNotFound {
+		t.Errorf("expected updating a missing document to be not found, got %v", err)
+	}
+
+	// An upserted document has only the fields of its key and update
+	if err = s.Upsert("institutions", Staging("institutions"), Key{"public_ukprn": "10000002", "release": "2019"}, Update{Set: map[string]interface{}{"country.code": "XK"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Update("institutions", Staging("institutions"), Key{"public_ukprn": "10000002", "name": nil}, Update{Set: map[string]interface{}{"name": "Upserted"}}); err != nil {
+		t.Errorf("expected a missing name to match a nil key, got %v", err)
+	}
+
+	if count, _ := s.Count("institutions", Staging("institutions"), Key{"release": "2019"}); count != 2 {
+		t.Errorf("expected 2 institutions of 2019 in staging, got %d", count)
+	}
+
+	if err = s.Swap("institutions", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+
+	var found institution
+	if err = s.FindOne("institutions", "institutions", key, &found); err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Name != "New" || len(found.Locations) != 1 || found.Locations[0].ID != "A" {
+		t.Errorf("expected the updated 2019 institution, got %+v", found)
+	}
+
+	var upserted institution
+	if err = s.FindOne("institutions", "institutions", Key{"country.code": "XK"}, &upserted); err != nil || upserted.Name != "Upserted" {
+		t.Errorf("expected to find the upserted institution by its nested field, got %+v %v", upserted, err)
+	}
+
+	var all []*institution
+	if err = s.Find("institutions", "institutions", Key{"public_ukprn": "10000001"}, &all); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 2 {
+		t.Errorf("expected both releases of the institution, got %d", len(all))
+	}
+
+	if err = s.FindOne("institutions", "institutions", Key{"public_ukprn": "10000003"}, &found); err != ErrNotFound {
+		t.Errorf("expected missing institution to be not found, got %v", err)
+	}
+
+	// Rolling back restores the generation holding only 2018
+	if err = s.Rollback("institutions", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, _ := s.Count("institutions", "institutions", nil); count != 1 {
+		t.Errorf("expected 1 institution once rolled back, got %d", count)
+	}
+
+	if err = s.Rollback("institutions", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testChanges(t *testing.T, s Store) {
+	var institutions []interface{}
+	for _, publicUKPRN := range []string{"20000001", "20000002", "20000003"} {
+		institutions = append(institutions, &institution{Name: "Institution " + publicUKPRN, PublicUKPRN: publicUKPRN, Release: "2019"})
+	}
+
+	if _, err := s.BulkInsert("changes", Staging("institutions"), institutions); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Swap("changes", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CopyToStaging("changes", "institutions"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The students of 20000002 are missing, so match a nil key
+	matched, failed, err := s.ApplyChanges("changes", Staging("institutions"), []Change{
+		{Document: &institution{Name: "Added", PublicUKPRN: "20000004", Release: "2019"}},
+		{Key: Key{"public_ukprn": "20000001", "release": "2019"}, Document: &institution{Name: "Replaced", PublicUKPRN: "20000001", Release: "2019"}},
+		{Key: Key{"public_ukprn": "20000002", "students": nil}, Update: &Update{Set: map[string]interface{}{"country.code": "XF"}}},
+		{Key: Key{"public_ukprn": "20000003", "release": "2019"}},
+		{Key: Key{"public_ukprn": "20000005", "release": "2019"}},
+	})
+	if err != nil || len(failed) > 0 {
+		t.Fatalf("unexpected apply changes failure %v %v", failed, err)
+	}
+
+	// 20000005 is not stored
+	if matched != 3 {
+		t.Errorf("expected 3 changes to match a document, got %d", matched)
+	}
+
+	var changed []institution
+	if err = s.Find("changes", Staging("institutions"), nil, &changed); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]string)
+	for _, i := range changed {
+		names[i.PublicUKPRN] = i.Name
+		if i.PublicUKPRN == "20000002" && (i.Country == nil || i.Country.Code != "XF") {
+			t.Errorf("expected the update to set the country of 20000002, got %+v", i.Country)
+		}
+	}
+
+	expected := map[string]string{"20000001": "Replaced", "20000002": "Institution 20000002", "20000004": "Added"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected institutions %v once changed, got %v", expected, names)
+	}
+
+	// The live collection is left as it was
+	if count, _ := s.Count("changes", "institutions", nil); count != 3 {
+		t.Errorf("expected 3 live institutions, got %d", count)
+	}
+}
+
+// TestRewriteUnmatched checks a rewrite with a change matching no document
+// leaves the live collection in place
+func TestRewriteUnmatched(t *testing.T) {
+	s := NewMemory()
+	if err := s.Insert("rewrite", "institutions", &institution{Name: "Stored", PublicUKPRN: "10000001"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// No institution of 2019 is stored
+	err := Rewrite(s, "rewrite", "institutions", nil, []Change{
+		{Key: Key{"public_ukprn": "10000001", "release": "2019"}, Update: &Update{Set: map[string]interface{}{"name": "Rewritten"}}},
+	}, 10)
+	if err == nil {
+		t.Fatal("expected a change matching no document to fail the rewrite")
+	}
+
+	var live institution
+	if err = s.FindOne("rewrite", "institutions", Key{"public_ukprn": "10000001"}, &live); err != nil {
+		t.Fatal(err)
+	}
+
+	if live.Name != "Stored" {
+		t.Errorf("expected the live collection not to be replaced, got %+v", live)
+	}
+}
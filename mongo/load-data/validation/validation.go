@@ -0,0 +1,139 @@
+// Package validation checks the csv files institution-builder and
+// course-builder read, without connecting to mongo, and reports the rows which
+// fail
+package validation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+)
+
+// maxProblems is the number of failing rows listed for each file in the
+// report, every failing row is still counted
+const maxProblems = 100
+
+// Statuses a file can finish validation with
+const (
+	StatusValid   = "valid"
+	StatusInvalid = "invalid"
+	StatusFailed  = "failed"
+)
+
+// FieldError reports a csv field which fails its checks
+type FieldError struct {
+	LineNumber int    `json:"line_number"`
+	Column     string `json:"column"`
+	Value      string `json:"value,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("column %s with value %q: %s", e.Column, e.Value, e.Reason)
+}
+
+// Result is the outcome of checking a csv file
+type Result struct {
+	File         string        `json:"file"`
+	Status       string        `json:"status"`
+	RowsRead     int           `json:"rows_read"`
+	RowsRejected int           `json:"rows_rejected"`
+	Error        string        `json:"error,omitempty"`
+	Problems     []*FieldError `json:"problems,omitempty"`
+}
+
+// File runs check against every row of the csv file of the source, named
+// without its extension, whose header row must have at least the given number
+// of columns
+func File(src *source.Source, fileName string, numberOfColumns int, check func(line []string) error) *Result {
+	result := &Result{File: fileName}
+
+	err := readFile(src, fileName, numberOfColumns, func(lineNumber int, line []string) {
+		result.RowsRead++
+
+		if err := check(line); err != nil {
+			problem, ok := err.(*FieldError)
+			if !ok {
+				problem = &FieldError{Reason: err.Error()}
+			}
+			problem.LineNumber = lineNumber
+
+			result.RowsRejected++
+			if len(result.Problems) < maxProblems {
+				result.Problems = append(result.Problems, problem)
+			}
+		}
+	})
+
+	switch {
+	case err != nil:
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	case result.RowsRejected > 0:
+		result.Status = StatusInvalid
+	default:
+		result.Status = StatusValid
+	}
+
+	return result
+}
+
+func readFile(src *source.Source, fileName string, numberOfColumns int, fn func(lineNumber int, line []string)) error {
+	csvFile, err := src.Open(fileName + ".csv")
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+
+	csvReader := csv.NewReader(bufio.NewReader(csvFile))
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return err
+	}
+
+	if len(header) < numberOfColumns {
+		return fmt.Errorf("expected at least %d columns but header row has %d", numberOfColumns, len(header))
+	}
+
+	lineNumber := 1
+	for {
+		line, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		lineNumber++
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+
+		fn(lineNumber, line)
+	}
+}
+
+// Required returns an error for the first of the columns, keyed by position,
+// which is blank
+func Required(line []string, columns map[int]string) error {
+	for i := 0; i < len(line); i++ {
+		if name, ok := columns[i]; ok && line[i] == "" {
+			return &FieldError{Column: name, Reason: "required value is blank"}
+		}
+	}
+
+	return nil
+}
+
+// WriteReport writes the results as a table followed by a JSON summary, both
+// naming the source the csv files were read from
+func WriteReport(w io.Writer, src *source.Source, results []*Result) error {
+	return src.WriteReport(w, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "FILE\tSTATUS\tREAD\tREJECTED\tERROR")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", r.File, r.Status, r.RowsRead, r.RowsRejected, r.Error)
+		}
+	}, results)
+}
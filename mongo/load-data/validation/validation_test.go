@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofs/alpha-scripts/mongo/load-data/source"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rows := "PUBUKPRN,UKPRN,NAME\n10000001,10000001,One\n,10000002,Two\n10000003,10000003,\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, "INSTITUTION.csv"), []byte(rows), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := source.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := File(src, "INSTITUTION", 3, func(line []string) error {
+		return Required(line, map[int]string{0: "PUBUKPRN", 2: "NAME"})
+	})
+
+	if result.Status != StatusInvalid || result.RowsRead != 3 || result.RowsRejected != 2 {
+		t.Fatalf("expected 2 of 3 rows to be rejected, got %+v", result)
+	}
+
+	if problem := result.Problems[0]; problem.LineNumber != 3 || problem.Column != "PUBUKPRN" {
+		t.Errorf("expected the blank PUBUKPRN on line 3, got %+v", problem)
+	}
+
+	if result = File(src, "INSTITUTION", 4, func([]string) error { return nil }); result.Status != StatusFailed {
+		t.Errorf("expected a header row with too few columns to fail, got %+v", result)
+	}
+
+	var report bytes.Buffer
+	if err = WriteReport(&report, src, []*Result{result}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(report.String(), "SOURCE "+dir+"\n") || !strings.Contains(report.String(), `"file": "INSTITUTION"`) {
+		t.Errorf("expected the report to name the source and file, got %s", report.String())
+	}
+}